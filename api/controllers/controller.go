@@ -1,11 +1,13 @@
 package controllers
 
 import (
-	"bytes"
-	"encoding/json"
 	"errors"
-	"log"
-	"os/exec"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/services"
+	"github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/migrations"
 )
 
 type Controller struct {
@@ -19,6 +21,21 @@ type Migration struct {
 	FileName string `yaml:"-"`
 	Version  string `yaml:"version"`
 	Name     string `yaml:"name"`
+	Path     string `yaml:"-" json:"path,omitempty"`
+
+	// NOTE: Only set for applied migrations
+	Checksum        string     `yaml:"-" json:"checksum,omitempty"`
+	Drifted         bool       `yaml:"-" json:"drifted,omitempty"`
+	Baselined       bool       `yaml:"-" json:"baselined,omitempty"`
+	Dirty           bool       `yaml:"-" json:"dirty,omitempty"`
+	FailedStatement *int       `yaml:"-" json:"failed_statement,omitempty"`
+	Batch           int        `yaml:"-" json:"batch,omitempty"`
+	AppliedAt       *time.Time `yaml:"-" json:"applied_at,omitempty"`
+	DurationMs      int64      `yaml:"-" json:"duration_ms,omitempty"`
+	AppliedBy       string     `yaml:"-" json:"applied_by,omitempty"`
+	Host            string     `yaml:"-" json:"host,omitempty"`
+	ToolVersion     string     `yaml:"-" json:"dm_version,omitempty"`
+	Source          string     `yaml:"-" json:"source,omitempty"`
 }
 
 type APIMigrations struct {
@@ -26,121 +43,104 @@ type APIMigrations struct {
 	Migrations []Migration `json:"migrations"`
 }
 
-type APIMessage struct {
-	Message string `json:"message"`
-}
-
-type APIError struct {
-	Error string `json:"error"`
+type HistoryEntry struct {
+	Id          int       `json:"id"`
+	Version     string    `json:"version"`
+	Name        string    `json:"name"`
+	Action      string    `json:"action"`
+	Error       string    `json:"error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	AppliedBy   string    `json:"applied_by,omitempty"`
+	Host        string    `json:"host,omitempty"`
+	ToolVersion string    `json:"dm_version,omitempty"`
+	Source      string    `json:"source,omitempty"`
 }
 
-func StatelessExecutionStrategy(args, flags []string) (interface{}, error) {
-
-	stdout, stderr, exited := CallCommand(args, flags)
-
-	outbuf, hasErrors := CheckForCommandErrors(stdout, stderr, exited)
-
-	if hasErrors {
-		message := APIError{Error: outbuf.String()}
-		return message, errors.New(outbuf.String())
-	}
-
-	data, err := ParseStdoutAsJSON(outbuf)
-
-	return data, err
+type APIHistory struct {
+	Count   int            `json:"total"`
+	Entries []HistoryEntry `json:"entries"`
 }
 
-func StatefulExecutionStrategy(args, flags []string) (interface{}, error) {
-	stdout, stderr, exited := CallCommand(args, flags)
-
-	outbuf, hasErrors := CheckForCommandErrors(stdout, stderr, exited)
-
-	if hasErrors {
-		var response APIError
-		err := json.Unmarshal(outbuf.Bytes(), &response)
-
-		if err != nil {
-			return APIError{Error: outbuf.String()}, errors.New(outbuf.String())
-		}
-
-		return response, err
-	}
-
-	data, err := ParseStdoutAsJSON(outbuf)
-
-	return data, err
+type APIMessage struct {
+	Message string `json:"message"`
 }
 
-func CallCommand(args []string, flags []string) (bytes.Buffer, bytes.Buffer, bool) {
-	var standardOutput, standardError bytes.Buffer
-	var exited bool
-
-	cmd := exec.Command("dm", append(args, flags...)...)
-
-	cmd.Stdout = &standardOutput
-	cmd.Stderr = &standardError
-
-	_ = cmd.Run()
-
-	exited = cmd.ProcessState.Exited()
-
-	return standardOutput, standardError, exited
+type APIError struct {
+	Error string `json:"error"`
 }
 
-func CheckForCommandErrors(stdout, stderr bytes.Buffer, exited bool) (bytes.Buffer, bool) {
-	// A populated stderr should always indicate the presence of errors.
-	if stderr.Len() != 0 {
-		log.Println("stderr was populated with", stderr.String())
-		return stderr, true
-	}
-
-	// Command was halted bu no further infomation was provided by the exec.Command
-	if exited && stderr.Len() == 0 && stdout.Len() == 0 {
-		log.Println("total silence from exec.Command")
-		return *bytes.NewBufferString("An error occcurred on the server while running the specified command"), true
+// NewAPIMigrations - The response of requests that list migrations.
+func NewAPIMigrations(list migrations.Migrations) APIMigrations {
+	response := APIMigrations{Count: len(list), Migrations: []Migration{}}
+
+	for _, migration := range list {
+		response.Migrations = append(response.Migrations, Migration{
+			Id:              migration.Id,
+			FileName:        migration.FileName,
+			Version:         migration.Version,
+			Name:            migration.Name,
+			Path:            migration.Path,
+			Checksum:        migration.Checksum,
+			Drifted:         migration.Drifted,
+			Baselined:       migration.Baselined,
+			Dirty:           migration.Dirty,
+			FailedStatement: migration.FailedStatement,
+			Batch:           migration.Batch,
+			AppliedAt:       migration.AppliedAt,
+			DurationMs:      migration.DurationMs,
+			AppliedBy:       migration.AppliedBy,
+			Host:            migration.Host,
+			ToolVersion:     migration.ToolVersion,
+			Source:          migration.Source,
+		})
 	}
 
-	return stdout, false
+	return response
 }
 
-func ParseStdoutAsJSON(stdout bytes.Buffer) (interface{}, error) {
-	var data interface{}
-	var err error
-
-	message := APIMessage{}
-	migrations := APIMigrations{}
-
-	err = ParseStdoutAsMessage(stdout, &message)
-	data = message
-
-	if err != nil {
-		err = ParseStdoutAsMigrations(stdout, &migrations)
-		data = migrations
+// NewAPIHistory - The response of history requests.
+func NewAPIHistory(history migrations.History) APIHistory {
+	response := APIHistory{Count: len(history), Entries: []HistoryEntry{}}
+
+	for _, entry := range history {
+		response.Entries = append(response.Entries, HistoryEntry{
+			Id:          entry.Id,
+			Version:     entry.Version,
+			Name:        entry.Name,
+			Action:      entry.Action,
+			Error:       entry.Error,
+			CreatedAt:   entry.CreatedAt,
+			DurationMs:  entry.DurationMs,
+			AppliedBy:   entry.AppliedBy,
+			Host:        entry.Host,
+			ToolVersion: entry.ToolVersion,
+			Source:      entry.Source,
+		})
 	}
 
-	return data, err
+	return response
 }
 
-func ParseStdoutAsMigrations(stdout bytes.Buffer, data *APIMigrations) error {
-	err := json.Unmarshal(stdout.Bytes(), &data.Migrations)
-
-	if err != nil {
-		log.Println("Error: unable to parse stdout as APIMigrations", err)
-		return err
+// StatusCode - Maps errors returned by the services to HTTP status codes.
+func StatusCode(err error) int {
+	switch {
+	case errors.As(err, new(*services.InvalidRequestError)), errors.As(err, new(*migrations.ValidationError)):
+		return config.BAD_REQUEST
+	case errors.As(err, new(*services.NotFoundError)):
+		return config.NOT_FOUND
+	case errors.As(err, new(*services.PolicyError)),
+		errors.As(err, new(*services.ConflictError)),
+		errors.As(err, new(*migrations.ChecksumMismatchError)),
+		errors.As(err, new(*migrations.LockError)),
+		errors.As(err, new(*migrations.DirtyError)):
+		return config.CONFLICT
+	default:
+		return config.SERVER_ERROR
 	}
-
-	data.Count = len(data.Migrations)
-
-	return nil
 }
 
-func ParseStdoutAsMessage(stdout bytes.Buffer, message *APIMessage) error {
-	err := json.Unmarshal(stdout.Bytes(), &message)
-
-	if err != nil {
-		log.Println("Error: unable to parse stdout as APIMessage", err)
-		return err
-	}
-
-	return nil
+// RespondWithError - Writes an error response with the status code of the error.
+func RespondWithError(ctx *gin.Context, err error) {
+	ctx.IndentedJSON(StatusCode(err), APIError{Error: err.Error()})
 }
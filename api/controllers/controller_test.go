@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/services"
+	"github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/migrations"
+)
+
+func TestStatusCode(t *testing.T) {
+	scenarios := []struct {
+		err      error
+		expected int
+	}{
+		{&services.InvalidRequestError{Reason: "invalid migration version or name"}, config.BAD_REQUEST},
+		{fmt.Errorf("%w: duplicate version", new(migrations.ValidationError)), config.BAD_REQUEST},
+		{&services.NotFoundError{Reason: "migration 'create_users' not found"}, config.NOT_FOUND},
+		{&services.PolicyError{Reason: "rollbacks are not allowed"}, config.CONFLICT},
+		{&services.ConflictError{Reason: "job is still running"}, config.CONFLICT},
+		{&migrations.ChecksumMismatchError{Versions: []string{"20221231054530129328"}}, config.CONFLICT},
+		{new(migrations.LockError), config.CONFLICT},
+		{new(migrations.DirtyError), config.CONFLICT},
+		{errors.New("connection refused"), config.SERVER_ERROR},
+	}
+
+	for _, scenario := range scenarios {
+		if status := StatusCode(scenario.err); status != scenario.expected {
+			t.Errorf(`%T: expected status %v, but got %v`, scenario.err, scenario.expected, status)
+		}
+	}
+}
+
+func TestRespondWithError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+
+	RespondWithError(ctx, &services.NotFoundError{Reason: "job 'abc' not found"})
+
+	var response APIError
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil || recorder.Code != config.NOT_FOUND {
+		t.Fatalf(`expected a %v response, but got %v %v`, config.NOT_FOUND, recorder.Code, recorder.Body.String())
+	}
+
+	if response.Error != "job 'abc' not found" {
+		t.Errorf(`expected error %v, but got %v`, "job 'abc' not found", response.Error)
+	}
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/metrics"
+	"github.com/oleoneto/dm/api/services"
+	"github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/migrations"
+)
+
+type MetricsController struct {
+	Controller
+	Service  *services.MigrationService
+	Registry *metrics.Registry
+}
+
+// Metrics - Describes the database and the activity of the API in the Prometheus text format.
+// Gauges that describe the database are left out while it cannot be read, rather than reported as zero. See `dm_database_up`.
+func (controller *MetricsController) Metrics(ctx *gin.Context) {
+	up := 1.0
+	gauges, err := controller.databaseGauges()
+
+	if err != nil {
+		_ = ctx.Error(err)
+		up = 0
+	}
+
+	gauges = append(gauges, metrics.Gauge{
+		Name:    "dm_database_up",
+		Help:    "Whether the database could be read (1) or not (0) when the metrics were scraped.",
+		Samples: []metrics.Sample{{Value: up}},
+	})
+
+	ctx.Status(config.SUCCESS)
+	ctx.Header("Content-Type", metrics.ContentType)
+
+	if err := controller.Registry.Write(ctx.Writer, gauges...); err != nil {
+		_ = ctx.Error(err)
+	}
+}
+
+// databaseGauges - Describes the migrations of the database. Returns an error if the database cannot be read.
+func (controller *MetricsController) databaseGauges() ([]metrics.Gauge, error) {
+	pending, err := controller.Service.Pending()
+
+	if err != nil {
+		return []metrics.Gauge{}, err
+	}
+
+	applied, err := controller.Service.Applied()
+
+	if err != nil {
+		return []metrics.Gauge{}, err
+	}
+
+	gauges := []metrics.Gauge{
+		{
+			Name:    "dm_pending_migrations",
+			Help:    "Migrations that have yet to be applied.",
+			Samples: []metrics.Sample{{Value: float64(pending.Len())}},
+		},
+		{
+			Name:    "dm_applied_migrations",
+			Help:    "Migrations registered in the schema table.",
+			Samples: []metrics.Sample{{Value: float64(applied.Len())}},
+		},
+	}
+
+	if last, found := lastApplied(applied); found {
+		gauges = append(gauges,
+			metrics.Gauge{
+				Name:    "dm_last_applied_migration_info",
+				Help:    "The version and name of the most recently applied migration.",
+				Samples: []metrics.Sample{{Labels: []string{"version", last.Version, "name", last.Name}, Value: 1}},
+			},
+			metrics.Gauge{
+				Name:    "dm_last_applied_migration_timestamp_seconds",
+				Help:    "When the most recently applied migration was applied, in seconds since the epoch.",
+				Samples: []metrics.Sample{{Value: float64(last.AppliedAt.UnixMilli()) / 1000}},
+			},
+		)
+	}
+
+	return gauges, nil
+}
+
+// lastApplied - The migration applied most recently. Migrations applied at the same time (i.e. in a transaction) are ordered by version.
+func lastApplied(applied migrations.Migrations) (migrations.Migration, bool) {
+	var last *migrations.Migration
+
+	for index := range applied {
+		migration := &applied[index]
+
+		if migration.AppliedAt == nil {
+			continue
+		}
+
+		if last == nil || migration.AppliedAt.After(*last.AppliedAt) || (migration.AppliedAt.Equal(*last.AppliedAt) && migration.Version > last.Version) {
+			last = migration
+		}
+	}
+
+	if last == nil {
+		return migrations.Migration{}, false
+	}
+
+	return *last, true
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/metrics"
+	"github.com/oleoneto/dm/config"
+)
+
+// scrape - Serves the metrics of a service for the SQLite database at the given path, with one migration.
+func scrape(t *testing.T, database string) string {
+	controller := MetricsController{Service: testService(t, database), Registry: metrics.NewRegistry()}
+
+	gin.SetMode(gin.TestMode)
+	app := gin.New()
+	app.GET("/metrics", controller.Metrics)
+
+	response := httptest.NewRecorder()
+	app.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if response.Code != config.SUCCESS {
+		t.Fatalf(`expected status %v, but got %v`, config.SUCCESS, response.Code)
+	}
+
+	return response.Body.String()
+}
+
+// ----------------------------------
+
+func TestMetrics(t *testing.T) {
+	// Scenario 1: The migrations of a database that can be read are described
+	output := scrape(t, filepath.Join(t.TempDir(), "dm.sqlite"))
+
+	for _, line := range []string{"dm_database_up 1\n", "dm_pending_migrations 1\n", "dm_applied_migrations 0\n"} {
+		if !strings.Contains(output, line) {
+			t.Errorf(`expected the output to contain %q, but got %v`, line, output)
+		}
+	}
+
+	// Scenario 2: The database is reported as down, rather than as having no pending migrations
+	output = scrape(t, filepath.Join(t.TempDir(), "missing", "dm.sqlite"))
+
+	if !strings.Contains(output, "dm_database_up 0\n") {
+		t.Errorf(`expected the database to be down, but got %v`, output)
+	}
+
+	if strings.Contains(output, "dm_pending_migrations") || strings.Contains(output, "dm_applied_migrations") {
+		t.Errorf(`expected the migrations of the database not to be described, but got %v`, output)
+	}
+}
@@ -1,96 +1,209 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/middleware"
+	"github.com/oleoneto/dm/api/services"
 	"github.com/oleoneto/dm/config"
 )
 
 type MigrationsController struct {
 	Controller
+	Service *services.MigrationService
 }
 
+// EventsHeartbeat - How often idle event streams are sent a comment.
+var EventsHeartbeat = 15 * time.Second
+
 type RequestBody struct {
 	Migration string `json:"migration"`
+
+	// NOTE: Only used for migrations
+	AcceptDrift bool `json:"accept_drift"`
+
+	// NOTE: Only used for rollbacks
+	Batch     int  `json:"batch"`
+	LastBatch bool `json:"last_batch"`
 }
 
 // MARK: - Stateless Operations
 // ------------------------------------------------------------------
 
 func (controller *MigrationsController) List(ctx *gin.Context) {
-	args := []string{"show", "all"}
-	flags := ctx.MustGet("command_flags").([]string)
+	ctx.IndentedJSON(config.SUCCESS, NewAPIMigrations(controller.service(ctx).All()))
+}
 
-	response, err := StatelessExecutionStrategy(args, flags)
+func (controller *MigrationsController) Applied(ctx *gin.Context) {
+	applied, err := controller.service(ctx).Applied()
 
 	if err != nil {
-		ctx.IndentedJSON(config.SERVER_ERROR, response)
+		RespondWithError(ctx, err)
 		return
 	}
 
-	ctx.IndentedJSON(config.SUCCESS, response)
+	ctx.IndentedJSON(config.SUCCESS, NewAPIMigrations(applied))
 }
 
-func (controller *MigrationsController) Applied(ctx *gin.Context) {
-	args := []string{"show", "applied"}
-	flags := ctx.MustGet("command_flags").([]string)
-
-	response, err := StatelessExecutionStrategy(args, flags)
+func (controller *MigrationsController) Pending(ctx *gin.Context) {
+	pending, err := controller.service(ctx).Pending()
 
 	if err != nil {
-		ctx.IndentedJSON(config.SERVER_ERROR, response)
+		RespondWithError(ctx, err)
 		return
 	}
 
-	ctx.IndentedJSON(config.SUCCESS, response)
+	ctx.IndentedJSON(config.SUCCESS, NewAPIMigrations(pending))
 }
 
-func (controller *MigrationsController) Pending(ctx *gin.Context) {
-	args := []string{"show", "pending"}
-	flags := ctx.MustGet("command_flags").([]string)
-
-	response, err := StatelessExecutionStrategy(args, flags)
+// History - Lists the history of the database. Supports the `migration`, `action`, `since`, `until`, and `limit` query params.
+func (controller *MigrationsController) History(ctx *gin.Context) {
+	history, err := controller.service(ctx).History(services.HistoryQuery{
+		Migration: ctx.Query("migration"),
+		Action:    ctx.Query("action"),
+		Since:     ctx.Query("since"),
+		Until:     ctx.Query("until"),
+		Limit:     ctx.Query("limit"),
+	})
 
 	if err != nil {
-		ctx.IndentedJSON(config.SERVER_ERROR, response)
+		RespondWithError(ctx, err)
 		return
 	}
 
-	ctx.IndentedJSON(config.SUCCESS, response)
+	ctx.IndentedJSON(config.SUCCESS, NewAPIHistory(history))
 }
 
 // MARK: - Stateful Operations (will affect the state of the database)
 // ------------------------------------------------------------------
 
+// Migrate - Starts a job that applies pending migrations. Responds with the job. See `Job()`.
 func (controller *MigrationsController) Migrate(ctx *gin.Context) {
 	var requestBody RequestBody
-	_ = ctx.Bind(&requestBody)
 
-	args := []string{"migrate"}
-	flags := append(ctx.MustGet("command_flags").([]string), requestBody.Migration)
+	if err := bindOptionalJSON(ctx, &requestBody); err != nil {
+		RespondWithError(ctx, err)
+		return
+	}
 
-	response, err := StatefulExecutionStrategy(args, flags)
+	job, err := controller.service(ctx).Migrate(services.MigrateRequest{
+		Migration:   requestBody.Migration,
+		AcceptDrift: requestBody.AcceptDrift,
+	})
 
 	if err != nil {
-		ctx.IndentedJSON(config.SERVER_ERROR, response)
+		RespondWithError(ctx, err)
 		return
 	}
 
-	ctx.IndentedJSON(config.ACCEPTED, response)
+	respondWithJob(ctx, job)
 }
 
+// Rollback - Starts a job that reverts applied migrations. Responds with the job. See `Job()`.
 func (controller *MigrationsController) Rollback(ctx *gin.Context) {
 	var requestBody RequestBody
-	_ = ctx.Bind(&requestBody)
 
-	args := []string{"rollback"}
-	flags := append(ctx.MustGet("command_flags").([]string), requestBody.Migration)
+	if err := bindOptionalJSON(ctx, &requestBody); err != nil {
+		RespondWithError(ctx, err)
+		return
+	}
+
+	job, err := controller.service(ctx).Rollback(services.RollbackRequest{
+		Migration: requestBody.Migration,
+		Batch:     requestBody.Batch,
+		LastBatch: requestBody.LastBatch,
+	})
+
+	if err != nil {
+		RespondWithError(ctx, err)
+		return
+	}
+
+	respondWithJob(ctx, job)
+}
+
+// Job - Shows the progress of a migration or rollback job.
+func (controller *MigrationsController) Job(ctx *gin.Context) {
+	job, err := controller.Service.Job(ctx.Param("id"))
+
+	if err != nil {
+		RespondWithError(ctx, err)
+		return
+	}
 
-	response, err := StatefulExecutionStrategy(args, flags)
+	ctx.IndentedJSON(config.SUCCESS, job)
+}
+
+// Events - Streams the progress of jobs as Server-Sent Events. Supports the `job` query param, which replays
+// the events of a job and ends the stream once it finishes. Without it, the events of every job are streamed.
+func (controller *MigrationsController) Events(ctx *gin.Context) {
+	replay, events, cancel, err := controller.Service.Events(ctx.Query("job"))
 
 	if err != nil {
-		ctx.IndentedJSON(config.SERVER_ERROR, response)
+		RespondWithError(ctx, err)
 		return
 	}
 
-	ctx.IndentedJSON(config.ACCEPTED, response)
+	defer cancel()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(config.SUCCESS)
+
+	for _, event := range replay {
+		ctx.SSEvent(event.Type, event)
+	}
+
+	ctx.Writer.Flush()
+
+	// NOTE: Comments keep idle connections from being closed by proxies
+	heartbeat := time.NewTicker(EventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, open := <-events:
+			if !open {
+				return
+			}
+
+			ctx.SSEvent(event.Type, event)
+		case <-heartbeat.C:
+			fmt.Fprint(ctx.Writer, ": heartbeat\n\n")
+		case <-ctx.Request.Context().Done():
+			return
+		}
+
+		ctx.Writer.Flush()
+	}
+}
+
+// respondWithJob - Responds with a job that was started, along with where its progress can be followed.
+func respondWithJob(ctx *gin.Context, job services.Job) {
+	ctx.Header("Location", fmt.Sprintf("%v/jobs/%v", ctx.FullPath(), job.ID))
+	ctx.IndentedJSON(config.ACCEPTED, job)
+}
+
+// service - The service used on behalf of the caller of the request.
+func (controller *MigrationsController) service(ctx *gin.Context) *services.MigrationService {
+	if caller, authenticated := middleware.CurrentCaller(ctx); authenticated {
+		return controller.Service.WithActor(caller.Name)
+	}
+
+	return controller.Service
+}
+
+// bindOptionalJSON - Reads the request body, if any.
+func bindOptionalJSON(ctx *gin.Context, body interface{}) error {
+	if err := ctx.ShouldBindJSON(body); err != nil && !errors.Is(err, io.EOF) {
+		return &services.InvalidRequestError{Reason: err.Error()}
+	}
+
+	return nil
 }
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/services"
+	"github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/stores"
+)
+
+// testService - A service for the SQLite database at the given path, with one migration.
+func testService(t *testing.T, database string) *services.MigrationService {
+	directory := t.TempDir()
+	migration := "-- +dm Engine sqlite3\n\n-- +dm Up\nCREATE TABLE items (id INTEGER);\n\n-- +dm Down\nDROP TABLE items;\n"
+
+	if err := os.WriteFile(filepath.Join(directory, "20221231054530129328_create_items.sql"), []byte(migration), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return services.NewMigrationService(config.APIConfig{
+		ConnectionString: database,
+		Directories:      []string{directory},
+		Table:            "_migrations",
+		Store:            stores.SQLite3{URL: database},
+	})
+}
+
+// testEventsServer - Serves the events of a service for an empty SQLite database with one migration.
+// Returns the server, its service, and a channel that receives a value whenever a stream ends.
+func testEventsServer(t *testing.T) (*httptest.Server, *services.MigrationService, chan struct{}) {
+	service := testService(t, filepath.Join(t.TempDir(), "dm.sqlite"))
+
+	controller := MigrationsController{Service: service}
+	ended := make(chan struct{}, 10)
+
+	gin.SetMode(gin.TestMode)
+	app := gin.New()
+	app.GET("/events", func(ctx *gin.Context) {
+		controller.Events(ctx)
+		ended <- struct{}{}
+	})
+
+	server := httptest.NewServer(app)
+	t.Cleanup(server.Close)
+
+	return server, service, ended
+}
+
+// readEvents - Reads the names of the events of a stream, until the given event or the end of the stream.
+func readEvents(scanner *bufio.Scanner, until string) []string {
+	names := []string{}
+
+	for scanner.Scan() {
+		if name := strings.TrimPrefix(scanner.Text(), "event:"); name != scanner.Text() {
+			names = append(names, name)
+
+			if name == until {
+				break
+			}
+		}
+	}
+
+	return names
+}
+
+func waitForStream(t *testing.T, ended chan struct{}) {
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		t.Fatalf(`expected the stream to end`)
+	}
+}
+
+// ----------------------------------
+
+func TestEvents(t *testing.T) {
+	server, service, ended := testEventsServer(t)
+
+	// Scenario 1: The events of every job are streamed as they happen
+	response, err := http.Get(server.URL + "/events")
+
+	if err != nil || response.StatusCode != config.SUCCESS || response.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf(`expected an event stream, but got %v (%v)`, response, err)
+	}
+
+	job, err := service.Migrate(services.MigrateRequest{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received := strings.Join(readEvents(bufio.NewScanner(response.Body), services.EventJobFinished), ",")
+	expected := "run_started,started,statement,succeeded,run_finished,job_finished"
+
+	if received != expected {
+		t.Errorf(`expected events %v, but got %v`, expected, received)
+	}
+
+	// Scenario 2: The stream ends once the client disconnects
+	response.Body.Close()
+	waitForStream(t, ended)
+
+	// Scenario 3: The events of a finished job are replayed, then the stream ends
+	response, err = http.Get(server.URL + "/events?job=" + job.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer response.Body.Close()
+
+	if received = strings.Join(readEvents(bufio.NewScanner(response.Body), ""), ","); received != expected {
+		t.Errorf(`expected events %v, but got %v`, expected, received)
+	}
+
+	waitForStream(t, ended)
+
+	// Scenario 4: Unknown jobs are not found
+	response, err = http.Get(server.URL + "/events?job=unknown")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != config.NOT_FOUND {
+		t.Errorf(`expected status %v, but got %v`, config.NOT_FOUND, response.StatusCode)
+	}
+}
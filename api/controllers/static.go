@@ -4,11 +4,16 @@ import (
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/services"
 	"github.com/oleoneto/dm/config"
 )
 
 type StaticController struct {
 	Controller
+	Service *services.MigrationService
+
+	// EventsURL - Where the live page reads events from. See `MigrationsController.Events()`.
+	EventsURL string
 }
 
 func (StaticController) Ping(ctx *gin.Context) {
@@ -24,30 +29,25 @@ func (StaticController) Documentation(ctx *gin.Context) {
 	ctx.HTML(config.SUCCESS, "swagger.html", gin.H{"title": "Database Migrator"})
 }
 
-func (controller *StaticController) Health(ctx *gin.Context) {
-	args := []string{"show", "pending"}
-	flags := ctx.MustGet("command_flags").([]string)
+// Live - A page that shows the progress of migrations as they run.
+func (controller *StaticController) Live(ctx *gin.Context) {
+	ctx.HTML(config.SUCCESS, "live.html", gin.H{"title": "Database Migrator", "events": controller.EventsURL})
+}
 
-	response, err := StatelessExecutionStrategy(args, flags)
+func (controller *StaticController) Health(ctx *gin.Context) {
+	pending, err := controller.Service.Pending()
 
+	// -- Unhealthy
 	if err != nil {
-		ctx.IndentedJSON(config.SERVER_ERROR, response)
+		RespondWithError(ctx, err)
 		return
 	}
 
-	data, castable := response.(APIMigrations)
-
-	if castable {
-		// -- Unhealthy
-		if len(data.Migrations) != 0 {
-			ctx.IndentedJSON(config.SERVER_ERROR, APIError{Error: fmt.Sprintf("%v pending migrations", len(data.Migrations))})
-			return
-		}
-
-		// -- Healthy
-		ctx.IndentedJSON(config.SUCCESS, APIMessage{Message: "No pending migrations"})
+	if pending.Len() != 0 {
+		ctx.IndentedJSON(config.SERVER_ERROR, APIError{Error: fmt.Sprintf("%v pending migrations", pending.Len())})
 		return
 	}
 
-	ctx.IndentedJSON(config.SUCCESS, response)
+	// -- Healthy
+	ctx.IndentedJSON(config.SUCCESS, APIMessage{Message: "No pending migrations"})
 }
@@ -0,0 +1,260 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oleoneto/dm/migrations"
+)
+
+// ContentType - The version of the Prometheus text format written by `Registry.Write()`.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	// MigrationBuckets - The upper bounds (in seconds) of the histograms of migration durations and lock waits.
+	MigrationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900}
+
+	// RequestBuckets - The upper bounds (in seconds) of the histogram of request durations.
+	RequestBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+/*
+Registry:
+
+	Collects the metrics of the API server and writes them in the Prometheus text format.
+
+	Migrations are counted from the events of the jobs started by the API. See `Observe()`.
+	Requests are counted by the metrics middleware. See `ObserveRequest()`.
+	Metrics that describe the database (i.e. pending migrations) are read when scraped and passed to `Write()`.
+*/
+type Registry struct {
+	mutex sync.Mutex
+
+	// migrations - Labelled by direction and result (succeeded or failed). Durations are labelled by direction.
+	migrations map[string]*series
+	durations  map[string]*series
+	lockWait   *histogram
+
+	// requests - Labelled by method, route, and status. Durations are labelled by method and route.
+	requests  map[string]*series
+	latencies map[string]*series
+}
+
+// Gauge - A value read when metrics are scraped.
+type Gauge struct {
+	Name    string
+	Help    string
+	Samples []Sample
+}
+
+// Sample - A value of a metric. Labels are listed as name and value pairs (i.e. "version", "20220504202600000000").
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// series - A counter or histogram with a given set of labels.
+type series struct {
+	labels    []string
+	value     float64
+	histogram *histogram
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		migrations: map[string]*series{},
+		durations:  map[string]*series{},
+		lockWait:   newHistogram(MigrationBuckets),
+		requests:   map[string]*series{},
+		latencies:  map[string]*series{},
+	}
+}
+
+// MARK: - Observations
+
+// Observe - Records the outcome and duration of migrations, and how long runs waited for the migration lock.
+func (registry *Registry) Observe(event migrations.Event) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	switch event.Type {
+	case migrations.EventRunStarted:
+		registry.lockWait.observe(milliseconds(event.LockWaitMs))
+	case migrations.EventSucceeded, migrations.EventFailed:
+		seriesOf(registry.migrations, nil, "direction", event.Direction, "result", event.Type).value++
+		seriesOf(registry.durations, MigrationBuckets, "direction", event.Direction).histogram.observe(milliseconds(event.DurationMs))
+	}
+}
+
+// ObserveRequest - Records a request served by the API. The route is the pattern of the request path (i.e. /v1/migrations/jobs/:id).
+func (registry *Registry) ObserveRequest(method, route string, status int, duration time.Duration) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	seriesOf(registry.requests, nil, "method", method, "route", route, "status", strconv.Itoa(status)).value++
+	seriesOf(registry.latencies, RequestBuckets, "method", method, "route", route).histogram.observe(duration.Seconds())
+}
+
+// MARK: - Exposition
+
+// Write - Writes the gauges, followed by the collected metrics, in the Prometheus text format.
+func (registry *Registry) Write(writer io.Writer, gauges ...Gauge) error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+
+	var builder strings.Builder
+
+	for _, gauge := range gauges {
+		if len(gauge.Samples) == 0 {
+			continue
+		}
+
+		header(&builder, gauge.Name, gauge.Help, "gauge")
+
+		for _, sample := range gauge.Samples {
+			line(&builder, gauge.Name, sample.Labels, sample.Value)
+		}
+	}
+
+	header(&builder, "dm_migrations_total", "Migrations applied (up) or rolled back (down) by the API, by result.", "counter")
+
+	for _, counter := range sorted(registry.migrations) {
+		line(&builder, "dm_migrations_total", counter.labels, counter.value)
+	}
+
+	header(&builder, "dm_migration_duration_seconds", "How long migrations run by the API took.", "histogram")
+
+	for _, durations := range sorted(registry.durations) {
+		durations.histogram.write(&builder, "dm_migration_duration_seconds", durations.labels)
+	}
+
+	header(&builder, "dm_lock_wait_seconds", "How long runs started by the API waited for the migration lock.", "histogram")
+	registry.lockWait.write(&builder, "dm_lock_wait_seconds", nil)
+
+	header(&builder, "dm_http_requests_total", "Requests served by the API, by route and status.", "counter")
+
+	for _, counter := range sorted(registry.requests) {
+		line(&builder, "dm_http_requests_total", counter.labels, counter.value)
+	}
+
+	header(&builder, "dm_http_request_duration_seconds", "How long the API took to serve requests, by route.", "histogram")
+
+	for _, latencies := range sorted(registry.latencies) {
+		latencies.histogram.write(&builder, "dm_http_request_duration_seconds", latencies.labels)
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// MARK: - Helpers
+
+// seriesOf - Returns the series with the given labels, creating it if needed. Histograms are created when buckets are given.
+func seriesOf(all map[string]*series, buckets []float64, labels ...string) *series {
+	key := strings.Join(labels, "\x00")
+
+	if _, found := all[key]; !found {
+		all[key] = &series{labels: labels}
+
+		if buckets != nil {
+			all[key].histogram = newHistogram(buckets)
+		}
+	}
+
+	return all[key]
+}
+
+// sorted - Series are written in a stable order, so consecutive scrapes are easy to compare.
+func sorted(all map[string]*series) []*series {
+	keys := []string{}
+
+	for key := range all {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	list := []*series{}
+
+	for _, key := range keys {
+		list = append(list, all[key])
+	}
+
+	return list
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (histogram *histogram) observe(value float64) {
+	histogram.count++
+	histogram.sum += value
+
+	for index, bound := range histogram.buckets {
+		if value <= bound {
+			histogram.counts[index]++
+		}
+	}
+}
+
+// write - Writes the cumulative buckets, the sum, and the count of the histogram.
+func (histogram *histogram) write(builder *strings.Builder, name string, labels []string) {
+	for index, bound := range histogram.buckets {
+		line(builder, name+"_bucket", append(append([]string{}, labels...), "le", formatted(bound)), float64(histogram.counts[index]))
+	}
+
+	line(builder, name+"_bucket", append(append([]string{}, labels...), "le", "+Inf"), float64(histogram.count))
+	line(builder, name+"_sum", labels, histogram.sum)
+	line(builder, name+"_count", labels, float64(histogram.count))
+}
+
+func header(builder *strings.Builder, name, help, kind string) {
+	fmt.Fprintf(builder, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+}
+
+func line(builder *strings.Builder, name string, labels []string, value float64) {
+	builder.WriteString(name)
+
+	if len(labels) != 0 {
+		pairs := []string{}
+
+		for index := 0; index+1 < len(labels); index += 2 {
+			pairs = append(pairs, fmt.Sprintf(`%v="%v"`, labels[index], escaped(labels[index+1])))
+		}
+
+		builder.WriteString("{" + strings.Join(pairs, ",") + "}")
+	}
+
+	builder.WriteString(" " + formatted(value) + "\n")
+}
+
+// escaped - Escapes backslashes, double quotes, and line feeds in label values, as required by the text format.
+func escaped(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatted(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func milliseconds(value int64) float64 {
+	return float64(value) / 1000
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/dm/migrations"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	registry := NewRegistry()
+
+	registry.Observe(migrations.Event{Type: migrations.EventRunStarted, Direction: "up", LockWaitMs: 20})
+	registry.Observe(migrations.Event{Type: migrations.EventStarted, Direction: "up", Version: "20221231054530129328"})
+	registry.Observe(migrations.Event{Type: migrations.EventSucceeded, Direction: "up", Version: "20221231054530129328", DurationMs: 200})
+	registry.Observe(migrations.Event{Type: migrations.EventFailed, Direction: "up", Version: "20221231054531293821", DurationMs: 7000})
+	registry.ObserveRequest("GET", "/v1/migrations", 200, 30*time.Millisecond)
+
+	var builder strings.Builder
+
+	err := registry.Write(&builder,
+		Gauge{Name: "dm_pending_migrations", Help: "Migrations that have yet to be applied.", Samples: []Sample{{Value: 3}}},
+		Gauge{Name: "dm_last_applied_migration_info", Help: "The most recently applied migration.", Samples: []Sample{
+			{Labels: []string{"version", "20221231054530129328", "name", "Create \"Items\"\nin C:\\db"}, Value: 1},
+		}},
+		Gauge{Name: "dm_unknown", Help: "Gauges without samples are not written."},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := builder.String()
+
+	expected := []string{
+		"# HELP dm_pending_migrations Migrations that have yet to be applied.\n# TYPE dm_pending_migrations gauge\ndm_pending_migrations 3\n",
+		`dm_last_applied_migration_info{version="20221231054530129328",name="Create \"Items\"\nin C:\\db"} 1`,
+		"# HELP dm_migrations_total Migrations applied (up) or rolled back (down) by the API, by result.\n# TYPE dm_migrations_total counter\n",
+		`dm_migrations_total{direction="up",result="failed"} 1`,
+		`dm_migrations_total{direction="up",result="succeeded"} 1`,
+		"# TYPE dm_migration_duration_seconds histogram\n",
+		`dm_migration_duration_seconds_bucket{direction="up",le="0.1"} 0`,
+		`dm_migration_duration_seconds_bucket{direction="up",le="0.5"} 1`,
+		`dm_migration_duration_seconds_bucket{direction="up",le="10"} 2`,
+		`dm_migration_duration_seconds_bucket{direction="up",le="+Inf"} 2`,
+		`dm_migration_duration_seconds_sum{direction="up"} 7.2`,
+		`dm_migration_duration_seconds_count{direction="up"} 2`,
+		`dm_lock_wait_seconds_bucket{le="0.01"} 0`,
+		`dm_lock_wait_seconds_bucket{le="0.05"} 1`,
+		"dm_lock_wait_seconds_sum 0.02\ndm_lock_wait_seconds_count 1\n",
+		`dm_http_requests_total{method="GET",route="/v1/migrations",status="200"} 1`,
+		`dm_http_request_duration_seconds_bucket{method="GET",route="/v1/migrations",le="0.025"} 0`,
+		`dm_http_request_duration_seconds_bucket{method="GET",route="/v1/migrations",le="0.05"} 1`,
+		`dm_http_request_duration_seconds_count{method="GET",route="/v1/migrations"} 1`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf(`expected the output to contain %q, but got %v`, line, output)
+		}
+	}
+
+	if strings.Contains(output, "dm_unknown") {
+		t.Errorf(`expected gauges without samples to be left out, but got %v`, output)
+	}
+
+	// NOTE: Counters are written in a stable order
+	if strings.Index(output, `result="failed"`) > strings.Index(output, `result="succeeded"`) {
+		t.Errorf(`expected series to be sorted, but got %v`, output)
+	}
+}
+
+func TestEscaped(t *testing.T) {
+	scenarios := []struct {
+		value    string
+		expected string
+	}{
+		{`CreateItems`, `CreateItems`},
+		{`C:\db`, `C:\\db`},
+		{`"quoted"`, `\"quoted\"`},
+		{"two\nlines", `two\nlines`},
+	}
+
+	for _, scenario := range scenarios {
+		if escaped := escaped(scenario.value); escaped != scenario.expected {
+			t.Errorf(`expected %v, but got %v`, scenario.expected, escaped)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/config"
+)
+
+const callerKey = "caller"
+
+var (
+	// ErrNoCredentials - The request does not carry the credentials checked by an authenticator.
+	ErrNoCredentials = errors.New("no credentials")
+)
+
+// Caller - The authenticated identity of a request.
+type Caller struct {
+	Name string
+	Role string
+
+	// Method - How the caller was authenticated (i.e. token, hmac, jwt).
+	Method string
+}
+
+/*
+Authenticator:
+
+	Identifies the caller of a request.
+
+	Returns `ErrNoCredentials` when the request does not carry the kind of credentials it checks,
+	so the next authenticator can be tried. Any other error rejects the request.
+*/
+type Authenticator interface {
+	Authenticate(ctx *gin.Context) (Caller, error)
+}
+
+// Authenticators - Builds the authenticators enabled by the settings.
+func Authenticators(settings config.AuthSettings) ([]Authenticator, error) {
+	authenticators := []Authenticator{}
+
+	if len(settings.Tokens) != 0 {
+		authenticators = append(authenticators, NewTokenAuthenticator(settings.Tokens))
+	}
+
+	if len(settings.HMAC) != 0 {
+		authenticators = append(authenticators, NewHMACAuthenticator(settings.HMAC))
+	}
+
+	if settings.JWT != nil {
+		authenticator, err := NewJWTAuthenticator(*settings.JWT)
+
+		if err != nil {
+			return authenticators, err
+		}
+
+		authenticators = append(authenticators, authenticator)
+	}
+
+	return authenticators, nil
+}
+
+// Authentication - Rejects requests that none of the authenticators accept. Every request is accepted when there are no authenticators.
+func Authentication(authenticators []Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if len(authenticators) == 0 {
+			ctx.Next()
+			return
+		}
+
+		for _, authenticator := range authenticators {
+			caller, err := authenticator.Authenticate(ctx)
+
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+
+			if err != nil {
+				// NOTE: The reason is only logged, so callers cannot probe credentials
+				log.Println("authentication failed:", err)
+				unauthorized(ctx, "invalid credentials")
+				return
+			}
+
+			ctx.Set(callerKey, caller)
+			ctx.Next()
+			return
+		}
+
+		unauthorized(ctx, "missing or invalid credentials")
+	}
+}
+
+// RequireRole - Rejects authenticated callers whose role does not include the required role.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		caller, authenticated := CurrentCaller(ctx)
+
+		// NOTE: Requests are not authenticated when no credentials are configured
+		if !authenticated {
+			ctx.Next()
+			return
+		}
+
+		if !config.RoleIncludes(caller.Role, role) {
+			ctx.AbortWithStatusJSON(config.FORBIDDEN, gin.H{
+				"error": fmt.Sprintf("'%v' (%v) is not allowed to %v", caller.Name, caller.Role, role),
+			})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// CurrentCaller - The caller of the request, if it was authenticated.
+func CurrentCaller(ctx *gin.Context) (Caller, bool) {
+	value, found := ctx.Get(callerKey)
+
+	if !found {
+		return Caller{}, false
+	}
+
+	caller, ok := value.(Caller)
+
+	return caller, ok
+}
+
+func unauthorized(ctx *gin.Context, reason string) {
+	ctx.Header("WWW-Authenticate", `Bearer realm="dm"`)
+	ctx.AbortWithStatusJSON(config.UNAUTHORIZED, gin.H{"error": reason})
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/config"
+)
+
+var testCredentials = []config.Credential{
+	{Name: "reader", Secret: "read-secret", Role: config.RoleRead},
+	{Name: "deployer", Secret: "migrate-secret", Role: config.RoleMigrate},
+	{Name: "admin", Secret: "rollback-secret", Role: config.RoleRollback},
+}
+
+// testServer - Serves the routes of the API that require each role, replying with the name of the caller.
+func testServer(authenticators ...Authenticator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	app := gin.New()
+	group := app.Group("/v1/migrations", Authentication(authenticators))
+
+	respond := func(ctx *gin.Context) {
+		caller, _ := CurrentCaller(ctx)
+		ctx.String(config.SUCCESS, caller.Name)
+	}
+
+	group.GET("", RequireRole(config.RoleRead), respond)
+	group.POST("", RequireRole(config.RoleMigrate), respond)
+	group.DELETE("", RequireRole(config.RoleRollback), respond)
+
+	return app
+}
+
+func serve(app *gin.Engine, request *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	app.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func withBearer(method, token string) *http.Request {
+	request := httptest.NewRequest(method, "/v1/migrations", nil)
+
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return request
+}
+
+// ----------------------------------
+
+func TestAuthentication(t *testing.T) {
+	app := testServer(NewTokenAuthenticator(testCredentials))
+
+	scenarios := []struct {
+		description string
+		request     *http.Request
+		status      int
+		caller      string
+	}{
+		{"missing credentials", withBearer(http.MethodGet, ""), config.UNAUTHORIZED, ""},
+		{"unknown token", withBearer(http.MethodGet, "not-a-secret"), config.UNAUTHORIZED, ""},
+		{"another scheme", func() *http.Request {
+			request := withBearer(http.MethodGet, "")
+			request.Header.Set("Authorization", "Basic read-secret")
+			return request
+		}(), config.UNAUTHORIZED, ""},
+		{"valid token", withBearer(http.MethodGet, "read-secret"), config.SUCCESS, "reader"},
+	}
+
+	for _, scenario := range scenarios {
+		response := serve(app, scenario.request)
+
+		if response.Code != scenario.status {
+			t.Errorf(`%v: expected status %v, but got %v`, scenario.description, scenario.status, response.Code)
+		}
+
+		if scenario.status == config.UNAUTHORIZED && response.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf(`%v: expected a WWW-Authenticate header`, scenario.description)
+		}
+
+		if scenario.caller != "" && response.Body.String() != scenario.caller {
+			t.Errorf(`%v: expected caller %v, but got %v`, scenario.description, scenario.caller, response.Body.String())
+		}
+	}
+}
+
+func TestAuthenticationDisabled(t *testing.T) {
+	app := testServer()
+
+	// NOTE: Every request is accepted, and roles are not checked
+	if response := serve(app, withBearer(http.MethodDelete, "")); response.Code != config.SUCCESS {
+		t.Errorf(`expected status %v, but got %v`, config.SUCCESS, response.Code)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	app := testServer(NewTokenAuthenticator(testCredentials))
+
+	scenarios := []struct {
+		token  string
+		method string
+		status int
+	}{
+		{"read-secret", http.MethodGet, config.SUCCESS},
+		{"read-secret", http.MethodPost, config.FORBIDDEN},
+		{"read-secret", http.MethodDelete, config.FORBIDDEN},
+		{"migrate-secret", http.MethodGet, config.SUCCESS},
+		{"migrate-secret", http.MethodPost, config.SUCCESS},
+		{"migrate-secret", http.MethodDelete, config.FORBIDDEN},
+		{"rollback-secret", http.MethodGet, config.SUCCESS},
+		{"rollback-secret", http.MethodPost, config.SUCCESS},
+		{"rollback-secret", http.MethodDelete, config.SUCCESS},
+	}
+
+	for _, scenario := range scenarios {
+		response := serve(app, withBearer(scenario.method, scenario.token))
+
+		if response.Code != scenario.status {
+			t.Errorf(`%v %v: expected status %v, but got %v`, scenario.token, scenario.method, scenario.status, response.Code)
+		}
+
+		if scenario.status == config.FORBIDDEN && !strings.Contains(response.Body.String(), "is not allowed to") {
+			t.Errorf(`%v %v: expected the refusal to be explained, but got %v`, scenario.token, scenario.method, response.Body.String())
+		}
+	}
+}
+
+func TestAuthenticatorsAreTriedInOrder(t *testing.T) {
+	app := testServer(NewTokenAuthenticator(testCredentials), NewHMACAuthenticator(testCredentials))
+
+	// Scenario 1: Requests without a token fall through to the next authenticator
+	request := signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", "{}", time.Now())
+
+	if response := serve(app, request); response.Code != config.SUCCESS || response.Body.String() != "deployer" {
+		t.Errorf(`expected the signed request to be accepted, but got %v %v`, response.Code, response.Body.String())
+	}
+
+	// Scenario 2: The first authenticator that accepts the request identifies the caller
+	request = signedRequest(http.MethodPost, "/v1/migrations", "deployer", "wrong-secret", "{}", time.Now())
+	request.Header.Set("Authorization", "Bearer migrate-secret")
+
+	if response := serve(app, request); response.Code != config.SUCCESS {
+		t.Errorf(`expected the token to be accepted first, but got %v`, response.Code)
+	}
+
+	// Scenario 3: Invalid credentials are rejected once no authenticator accepts the request
+	request.Header.Del("Authorization")
+
+	if response := serve(app, request); response.Code != config.UNAUTHORIZED {
+		t.Errorf(`expected status %v, but got %v`, config.UNAUTHORIZED, response.Code)
+	}
+}
@@ -18,13 +18,6 @@ func ConfigurationMiddleware(configuration config.APIConfig) gin.HandlerFunc {
 			return
 		}
 
-		flags := []string{
-			"--output-format", "json",
-			"--directory", configuration.Directory,
-			"--table", configuration.Table,
-		}
-
-		ctx.Set("command_flags", flags)
 		ctx.Next()
 	}
 }
@@ -8,6 +8,7 @@ func CorsHeaders(origin string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Header("Access-Control-Allow-Methods", "PUT, POST, GET, DELETE, OPTIONS")
 		ctx.Header("Access-Control-Allow-Origin", origin)
+		ctx.Header("Access-Control-Allow-Headers", "Authorization, Content-Type, X-DM-Key, X-DM-Timestamp, X-DM-Signature")
 		ctx.Next()
 	}
 }
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/config"
+)
+
+const (
+	HMACKeyHeader       = "X-DM-Key"
+	HMACTimestampHeader = "X-DM-Timestamp"
+	HMACSignatureHeader = "X-DM-Signature"
+
+	// HMACMaxSkew - How old (or how far in the future) the timestamp of a signed request can be.
+	HMACMaxSkew = 5 * time.Minute
+)
+
+/*
+HMACAuthenticator:
+
+	Accepts requests signed with a shared secret. Signed requests set three headers:
+
+	X-DM-Key: 		the name of the credential
+	X-DM-Timestamp: the time of the request, in seconds since the Unix epoch
+	X-DM-Signature: the hex-encoded HMAC-SHA256 of "<METHOD>\n<PATH AND QUERY>\n<TIMESTAMP>\n<BODY>"
+*/
+type HMACAuthenticator struct {
+	credentials []config.Credential
+}
+
+func NewHMACAuthenticator(credentials []config.Credential) HMACAuthenticator {
+	return HMACAuthenticator{credentials: credentials}
+}
+
+func (authenticator HMACAuthenticator) Authenticate(ctx *gin.Context) (Caller, error) {
+	name := ctx.GetHeader(HMACKeyHeader)
+
+	if name == "" {
+		return Caller{}, ErrNoCredentials
+	}
+
+	var credential *config.Credential
+
+	for index := range authenticator.credentials {
+		if authenticator.credentials[index].Name == name {
+			credential = &authenticator.credentials[index]
+		}
+	}
+
+	if credential == nil {
+		return Caller{}, fmt.Errorf("unknown hmac key '%v'", name)
+	}
+
+	timestamp := ctx.GetHeader(HMACTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return Caller{}, fmt.Errorf("invalid hmac timestamp '%v'", timestamp)
+	}
+
+	if skew := time.Since(time.Unix(seconds, 0)); skew > HMACMaxSkew || skew < -HMACMaxSkew {
+		return Caller{}, fmt.Errorf("hmac timestamp of '%v' is outside the allowed window", name)
+	}
+
+	signature, err := hex.DecodeString(ctx.GetHeader(HMACSignatureHeader))
+
+	if err != nil {
+		return Caller{}, fmt.Errorf("invalid hmac signature from '%v'", name)
+	}
+
+	body, err := io.ReadAll(ctx.Request.Body)
+
+	if err != nil {
+		return Caller{}, err
+	}
+
+	// NOTE: The body is read again by the controllers
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	if !hmac.Equal(signature, SignRequest(credential.ExpandedSecret(), ctx.Request.Method, ctx.Request.URL.RequestURI(), timestamp, body)) {
+		return Caller{}, fmt.Errorf("hmac signature mismatch for '%v'", name)
+	}
+
+	return Caller{Name: credential.Name, Role: credential.Role, Method: "hmac"}, nil
+}
+
+// SignRequest - Computes the signature of a request. See `HMACAuthenticator`.
+func SignRequest(secret, method, uri, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%v\n%v\n%v\n", method, uri, timestamp)))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
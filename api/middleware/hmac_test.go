@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/config"
+)
+
+func signedRequest(method, uri, key, secret, body string, at time.Time) *http.Request {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	request := httptest.NewRequest(method, uri, strings.NewReader(body))
+	request.Header.Set(HMACKeyHeader, key)
+	request.Header.Set(HMACTimestampHeader, timestamp)
+	request.Header.Set(HMACSignatureHeader, hex.EncodeToString(SignRequest(secret, method, uri, timestamp, []byte(body))))
+
+	return request
+}
+
+// ----------------------------------
+
+func TestHMACAuthenticator(t *testing.T) {
+	app := testServer(NewHMACAuthenticator(testCredentials))
+	body := `{"migration":"create_items"}`
+
+	scenarios := []struct {
+		description string
+		request     *http.Request
+		status      int
+	}{
+		{"valid signature", signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", body, time.Now()), config.SUCCESS},
+		{"slight clock skew", signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", body, time.Now().Add(time.Minute)), config.SUCCESS},
+		{"unknown key", signedRequest(http.MethodPost, "/v1/migrations", "intruder", "migrate-secret", body, time.Now()), config.UNAUTHORIZED},
+		{"wrong secret", signedRequest(http.MethodPost, "/v1/migrations", "deployer", "rollback-secret", body, time.Now()), config.UNAUTHORIZED},
+		{"expired timestamp", signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", body, time.Now().Add(-HMACMaxSkew-time.Minute)), config.UNAUTHORIZED},
+		{"future timestamp", signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", body, time.Now().Add(HMACMaxSkew+time.Minute)), config.UNAUTHORIZED},
+		{"tampered body", func() *http.Request {
+			request := signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", body, time.Now())
+			request.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"migration":"drop_items"}`)).Body
+			return request
+		}(), config.UNAUTHORIZED},
+		{"tampered method", func() *http.Request {
+			request := signedRequest(http.MethodPost, "/v1/migrations", "admin", "rollback-secret", body, time.Now())
+			request.Method = http.MethodDelete
+			return request
+		}(), config.UNAUTHORIZED},
+		{"invalid timestamp", func() *http.Request {
+			request := signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", body, time.Now())
+			request.Header.Set(HMACTimestampHeader, "yesterday")
+			return request
+		}(), config.UNAUTHORIZED},
+		{"invalid signature", func() *http.Request {
+			request := signedRequest(http.MethodPost, "/v1/migrations", "deployer", "migrate-secret", body, time.Now())
+			request.Header.Set(HMACSignatureHeader, "not-hex")
+			return request
+		}(), config.UNAUTHORIZED},
+	}
+
+	for _, scenario := range scenarios {
+		if response := serve(app, scenario.request); response.Code != scenario.status {
+			t.Errorf(`%v: expected status %v, but got %v`, scenario.description, scenario.status, response.Code)
+		}
+	}
+}
+
+func TestHMACAuthenticatorKeepsBody(t *testing.T) {
+	body := `{"migration":"create_items"}`
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = signedRequest(http.MethodPost, "/v1/migrations?dry=1", "deployer", "migrate-secret", body, time.Now())
+
+	caller, err := NewHMACAuthenticator(testCredentials).Authenticate(ctx)
+
+	if err != nil || caller.Name != "deployer" || caller.Method != "hmac" {
+		t.Fatalf(`expected the request to be authenticated, but got %v (%v)`, caller, err)
+	}
+
+	// NOTE: Controllers read the body after the signature was checked
+	read, _ := io.ReadAll(ctx.Request.Body)
+
+	if string(read) != body {
+		t.Errorf(`expected body %v, but got %v`, body, string(read))
+	}
+}
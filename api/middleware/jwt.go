@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/config"
+)
+
+/*
+JWTAuthenticator:
+
+	Accepts JSON Web Tokens sent as `Authorization: Bearer <token>`.
+
+	Tokens must be signed with RS256 or ES256 by one of the keys of a local JWKS file.
+	The role of the caller is read from a claim (role, by default) and its identity from another (sub, by default).
+*/
+type JWTAuthenticator struct {
+	settings config.JWTSettings
+	keys     map[string]crypto.PublicKey
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// NewJWTAuthenticator - Reads the public keys of the JWKS file.
+func NewJWTAuthenticator(settings config.JWTSettings) (JWTAuthenticator, error) {
+	authenticator := JWTAuthenticator{settings: settings, keys: map[string]crypto.PublicKey{}}
+
+	if authenticator.settings.RoleClaim == "" {
+		authenticator.settings.RoleClaim = "role"
+	}
+
+	if authenticator.settings.IdentityClaim == "" {
+		authenticator.settings.IdentityClaim = "sub"
+	}
+
+	contents, err := os.ReadFile(settings.JWKS)
+
+	if err != nil {
+		return authenticator, fmt.Errorf("unable to read jwks: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err = json.Unmarshal(contents, &set); err != nil {
+		return authenticator, fmt.Errorf("invalid jwks %v: %w", settings.JWKS, err)
+	}
+
+	for _, key := range set.Keys {
+		publicKey, err := key.publicKey()
+
+		if err != nil {
+			return authenticator, fmt.Errorf("invalid jwks %v: key '%v': %w", settings.JWKS, key.Kid, err)
+		}
+
+		authenticator.keys[key.Kid] = publicKey
+	}
+
+	if len(authenticator.keys) == 0 {
+		return authenticator, fmt.Errorf("invalid jwks %v: no keys", settings.JWKS)
+	}
+
+	return authenticator, nil
+}
+
+func (authenticator JWTAuthenticator) Authenticate(ctx *gin.Context) (Caller, error) {
+	token, found := bearerToken(ctx)
+
+	if !found || strings.Count(token, ".") != 2 {
+		return Caller{}, ErrNoCredentials
+	}
+
+	claims, err := authenticator.verify(token)
+
+	if err != nil {
+		return Caller{}, err
+	}
+
+	name, _ := claims[authenticator.settings.IdentityClaim].(string)
+	role, _ := claims[authenticator.settings.RoleClaim].(string)
+
+	if name == "" {
+		return Caller{}, fmt.Errorf("jwt has no '%v' claim", authenticator.settings.IdentityClaim)
+	}
+
+	if !config.IsRole(role) {
+		return Caller{}, fmt.Errorf("jwt of '%v' has an unknown role '%v'", name, role)
+	}
+
+	return Caller{Name: name, Role: role, Method: "jwt"}, nil
+}
+
+// verify - Checks the signature and the registered claims (exp, nbf, iss, aud) of a token. Returns its claims.
+func (authenticator JWTAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+
+	var header jwtHeader
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid jwt header: %w", err)
+	}
+
+	key, found := authenticator.keys[header.Kid]
+
+	if !found {
+		return nil, fmt.Errorf("jwt signed by unknown key '%v'", header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt signature: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	if err = verifySignature(header.Alg, key, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{}
+
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid jwt claims: %w", err)
+	}
+
+	now := float64(time.Now().Unix())
+
+	if exp, found := claims["exp"].(float64); !found || now >= exp {
+		return nil, errors.New("jwt is expired")
+	}
+
+	if nbf, found := claims["nbf"].(float64); found && now < nbf {
+		return nil, errors.New("jwt is not valid yet")
+	}
+
+	if issuer := authenticator.settings.Issuer; issuer != "" && claims["iss"] != issuer {
+		return nil, fmt.Errorf("jwt issued by '%v'", claims["iss"])
+	}
+
+	if audience := authenticator.settings.Audience; audience != "" && !hasAudience(claims["aud"], audience) {
+		return nil, fmt.Errorf("jwt not intended for '%v'", audience)
+	}
+
+	return claims, nil
+}
+
+func verifySignature(algorithm string, key crypto.PublicKey, digest, signature []byte) error {
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		if algorithm != "RS256" {
+			break
+		}
+
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest, signature); err != nil {
+			return errors.New("invalid jwt signature")
+		}
+
+		return nil
+	case *ecdsa.PublicKey:
+		if algorithm != "ES256" {
+			break
+		}
+
+		if len(signature) != 64 {
+			return errors.New("invalid jwt signature")
+		}
+
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return errors.New("invalid jwt signature")
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unsupported jwt algorithm '%v'", algorithm)
+}
+
+// hasAudience - The aud claim is either a string or a list of strings.
+func hasAudience(claim interface{}, audience string) bool {
+	switch value := claim.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, item := range value {
+			if item == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	contents, err := base64.RawURLEncoding.DecodeString(segment)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(contents, value)
+}
+
+func (key jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if key.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve '%v'", key.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := base64.RawURLEncoding.DecodeString(key.Y)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%v'", key.Kty)
+	}
+}
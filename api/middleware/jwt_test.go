@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/dm/config"
+)
+
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+// testJWKS - Writes a JWKS file with an RSA key (kid rsa) and an EC key (kid ec).
+func testJWKS(t *testing.T) (string, testKeys) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded := func(value []byte) string { return base64.RawURLEncoding.EncodeToString(value) }
+
+	set := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": encoded(rsaKey.N.Bytes()), "e": encoded(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encoded(ecKey.X.FillBytes(make([]byte, 32))), "y": encoded(ecKey.Y.FillBytes(make([]byte, 32)))},
+		},
+	}
+
+	contents, _ := json.Marshal(set)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+
+	if err = os.WriteFile(path, contents, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+// signJWT - Signs the claims with the key matching the algorithm. Unknown algorithms produce an empty signature.
+func signJWT(t *testing.T, keys testKeys, alg, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	var signature []byte
+
+	switch alg {
+	case "RS256":
+		signed, err := rsa.SignPKCS1v15(rand.Reader, keys.rsa, crypto.SHA256, digest[:])
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signature = signed
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, keys.ec, digest[:])
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func claims(changes map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub":  "alice",
+		"role": config.RoleMigrate,
+		"iss":  "https://auth.example.com",
+		"aud":  "dm",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+
+		claims[name] = value
+	}
+
+	return claims
+}
+
+// ----------------------------------
+
+func TestJWTAuthenticator(t *testing.T) {
+	path, keys := testJWKS(t)
+
+	authenticator, err := NewJWTAuthenticator(config.JWTSettings{JWKS: path, Issuer: "https://auth.example.com", Audience: "dm"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := testServer(authenticator)
+
+	tampered := func() string {
+		parts := strings.Split(signJWT(t, keys, "RS256", "rsa", claims(nil)), ".")
+		payload, _ := json.Marshal(claims(map[string]interface{}{"role": config.RoleRollback}))
+		parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+		return strings.Join(parts, ".")
+	}
+
+	scenarios := []struct {
+		description string
+		token       string
+		method      string
+		status      int
+	}{
+		{"valid RS256", signJWT(t, keys, "RS256", "rsa", claims(nil)), http.MethodPost, config.SUCCESS},
+		{"valid ES256", signJWT(t, keys, "ES256", "ec", claims(nil)), http.MethodPost, config.SUCCESS},
+		{"audience list", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"aud": []string{"other", "dm"}})), http.MethodPost, config.SUCCESS},
+		{"expired", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"exp": time.Now().Add(-time.Minute).Unix()})), http.MethodPost, config.UNAUTHORIZED},
+		{"without expiration", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"exp": nil})), http.MethodPost, config.UNAUTHORIZED},
+		{"not valid yet", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})), http.MethodPost, config.UNAUTHORIZED},
+		{"alg none", signJWT(t, keys, "none", "rsa", claims(nil)), http.MethodPost, config.UNAUTHORIZED},
+		{"alg HS256", signJWT(t, keys, "HS256", "rsa", claims(nil)), http.MethodPost, config.UNAUTHORIZED},
+		{"alg of another key type", signJWT(t, keys, "ES256", "rsa", claims(nil)), http.MethodPost, config.UNAUTHORIZED},
+		{"unknown kid", signJWT(t, keys, "RS256", "unknown", claims(nil)), http.MethodPost, config.UNAUTHORIZED},
+		{"wrong audience", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"aud": "billing"})), http.MethodPost, config.UNAUTHORIZED},
+		{"wrong issuer", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"iss": "https://evil.example.com"})), http.MethodPost, config.UNAUTHORIZED},
+		{"tampered claims", tampered(), http.MethodDelete, config.UNAUTHORIZED},
+		{"unknown role", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"role": "admin"})), http.MethodGet, config.UNAUTHORIZED},
+		{"without identity", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"sub": nil})), http.MethodGet, config.UNAUTHORIZED},
+		{"read role calling rollback", signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"role": config.RoleRead})), http.MethodDelete, config.FORBIDDEN},
+	}
+
+	for _, scenario := range scenarios {
+		response := serve(app, withBearer(scenario.method, scenario.token))
+
+		if response.Code != scenario.status {
+			t.Errorf(`%v: expected status %v, but got %v (%v)`, scenario.description, scenario.status, response.Code, response.Body.String())
+		}
+
+		if scenario.status == config.SUCCESS && response.Body.String() != "alice" {
+			t.Errorf(`%v: expected caller alice, but got %v`, scenario.description, response.Body.String())
+		}
+	}
+}
+
+func TestJWTAuthenticatorClaims(t *testing.T) {
+	path, keys := testJWKS(t)
+
+	authenticator, err := NewJWTAuthenticator(config.JWTSettings{JWKS: path, RoleClaim: "dm_role", IdentityClaim: "email"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := signJWT(t, keys, "RS256", "rsa", claims(map[string]interface{}{"dm_role": config.RoleRollback, "email": "bob@example.com"}))
+	response := serve(testServer(authenticator), withBearer(http.MethodDelete, token))
+
+	if response.Code != config.SUCCESS || response.Body.String() != "bob@example.com" {
+		t.Errorf(`expected the configured claims to be used, but got %v %v`, response.Code, response.Body.String())
+	}
+}
+
+func TestNewJWTAuthenticator(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.json")
+	_ = os.WriteFile(empty, []byte(`{"keys":[]}`), 0o600)
+
+	unsupported := filepath.Join(t.TempDir(), "unsupported.json")
+	_ = os.WriteFile(unsupported, []byte(`{"keys":[{"kty":"oct","kid":"shared","k":"c2VjcmV0"}]}`), 0o600)
+
+	scenarios := []struct {
+		path   string
+		reason string
+	}{
+		{filepath.Join(t.TempDir(), "missing.json"), "unable to read jwks"},
+		{empty, "no keys"},
+		{unsupported, "unsupported key type"},
+	}
+
+	for _, scenario := range scenarios {
+		_, err := NewJWTAuthenticator(config.JWTSettings{JWKS: scenario.path})
+
+		if err == nil || !strings.Contains(err.Error(), scenario.reason) {
+			t.Errorf(`expected error %v, but got %v`, scenario.reason, err)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/metrics"
+)
+
+// Metrics - Records the method, route, status, and duration of every request.
+// Requests that match no route are recorded under the `unmatched` route, so unknown paths cannot grow the number of series.
+func Metrics(registry *metrics.Registry) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		started := time.Now()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+
+		if route == "" {
+			route = "unmatched"
+		}
+
+		registry.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(started))
+	}
+}
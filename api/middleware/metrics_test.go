@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/api/metrics"
+	"github.com/oleoneto/dm/config"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := metrics.NewRegistry()
+
+	app := gin.New()
+	app.Use(Metrics(registry))
+	app.GET("/v1/migrations/jobs/:id", func(ctx *gin.Context) { ctx.String(config.NOT_FOUND, "job not found") })
+	app.GET("/metrics", func(ctx *gin.Context) {
+		ctx.Header("Content-Type", metrics.ContentType)
+		_ = registry.Write(ctx.Writer)
+	})
+
+	serve(app, httptest.NewRequest(http.MethodGet, "/v1/migrations/jobs/7ec8359f", nil))
+	serve(app, httptest.NewRequest(http.MethodGet, "/v1/migrations/jobs/0ff3a143", nil))
+	serve(app, httptest.NewRequest(http.MethodGet, "/wp-admin/\"login\"", nil))
+
+	response := serve(app, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	output := response.Body.String()
+
+	if response.Header().Get("Content-Type") != metrics.ContentType {
+		t.Errorf(`expected content type %v, but got %v`, metrics.ContentType, response.Header().Get("Content-Type"))
+	}
+
+	// NOTE: Requests are labelled by route rather than path, and the scrape itself is recorded once it is served
+	expected := []string{
+		"# HELP dm_http_requests_total Requests served by the API, by route and status.\n# TYPE dm_http_requests_total counter\n",
+		`dm_http_requests_total{method="GET",route="/v1/migrations/jobs/:id",status="404"} 2`,
+		`dm_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		"# TYPE dm_http_request_duration_seconds histogram\n",
+		`dm_http_request_duration_seconds_bucket{method="GET",route="/v1/migrations/jobs/:id",le="+Inf"} 2`,
+		`dm_http_request_duration_seconds_count{method="GET",route="/v1/migrations/jobs/:id"} 2`,
+		`dm_http_request_duration_seconds_sum{method="GET",route="unmatched"} `,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf(`expected the output to contain %q, but got %v`, line, output)
+		}
+	}
+
+	for _, unexpected := range []string{"7ec8359f", "wp-admin", `route="/metrics"`} {
+		if strings.Contains(output, unexpected) {
+			t.Errorf(`expected the output not to contain %v, but got %v`, unexpected, output)
+		}
+	}
+
+	if response = serve(app, httptest.NewRequest(http.MethodGet, "/metrics", nil)); !strings.Contains(response.Body.String(), `route="/metrics",status="200"} 1`) {
+		t.Errorf(`expected the previous scrape to be recorded, but got %v`, response.Body.String())
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/oleoneto/dm/config"
+)
+
+// TokenAuthenticator - Accepts static tokens sent as `Authorization: Bearer <token>`.
+type TokenAuthenticator struct {
+	credentials []config.Credential
+}
+
+func NewTokenAuthenticator(credentials []config.Credential) TokenAuthenticator {
+	return TokenAuthenticator{credentials: credentials}
+}
+
+func (authenticator TokenAuthenticator) Authenticate(ctx *gin.Context) (Caller, error) {
+	token, found := bearerToken(ctx)
+
+	if !found {
+		return Caller{}, ErrNoCredentials
+	}
+
+	for _, credential := range authenticator.credentials {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(credential.ExpandedSecret())) == 1 {
+			return Caller{Name: credential.Name, Role: credential.Role, Method: "token"}, nil
+		}
+	}
+
+	// NOTE: The token may be a JWT
+	return Caller{}, ErrNoCredentials
+}
+
+func bearerToken(ctx *gin.Context) (string, bool) {
+	scheme, token, found := strings.Cut(ctx.GetHeader("Authorization"), " ")
+
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
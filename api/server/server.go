@@ -10,14 +10,13 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/oleoneto/dm/api/controllers"
+	"github.com/oleoneto/dm/api/metrics"
 	"github.com/oleoneto/dm/api/middleware"
+	"github.com/oleoneto/dm/api/services"
 	"github.com/oleoneto/dm/config"
 )
 
 var (
-	staticController     = controllers.StaticController{}
-	migrationsController = controllers.MigrationsController{}
-
 	//go:embed public/*
 	assets embed.FS
 
@@ -28,17 +27,28 @@ var (
 /*
 Application routes:
 
+GET 		/metrics
 GET 		/${API_VERSION}
 GET 		/${API_VERSION}/docs
+GET 		/${API_VERSION}/live
 GET 		/${API_VERSION}/health
 GET 		/${API_VERSION}/migrations
 POST 		/${API_VERSION}/migrations
 DELETE 	/${API_VERSION}/migrations
 GET 		/${API_VERSION}/migrations/applied
 GET 		/${API_VERSION}/migrations/pending
+GET 		/${API_VERSION}/migrations/history
+GET 		/${API_VERSION}/migrations/jobs/:id
+GET 		/${API_VERSION}/migrations/events
 */
 
-func API(conf config.APIConfig) *gin.Engine {
+func API(conf config.APIConfig) (*gin.Engine, error) {
+	authenticators, err := middleware.Authenticators(conf.Auth)
+
+	if err != nil {
+		return nil, err
+	}
+
 	if conf.DebugMode {
 		gin.SetMode(gin.DebugMode)
 	} else {
@@ -47,6 +57,20 @@ func API(conf config.APIConfig) *gin.Engine {
 
 	app := gin.Default()
 
+	service := services.NewMigrationService(conf)
+	staticController := controllers.StaticController{
+		Service:   service,
+		EventsURL: fmt.Sprintf("/%v/%v/events", sanitized(conf.Version), sanitized(conf.Namespace)),
+	}
+	migrationsController := controllers.MigrationsController{Service: service}
+
+	registry := metrics.NewRegistry()
+	service.Observe(registry.Observe)
+	metricsController := controllers.MetricsController{Service: service, Registry: registry}
+
+	// Metrics
+	app.Use(middleware.Metrics(registry))
+
 	// CORS
 	app.Use(middleware.CorsHeaders(conf.AllowedHost))
 
@@ -55,23 +79,41 @@ func API(conf config.APIConfig) *gin.Engine {
 	app.StaticFS("static", assetsFS(assets))
 
 	app.GET("/", staticController.Ping)
+	app.GET(
+		"/metrics",
+		middleware.ConfigurationMiddleware(conf),
+		middleware.Authentication(authenticators),
+		middleware.RequireRole(config.RoleRead),
+		metricsController.Metrics,
+	)
 
 	versionedGroup := app.Group(fmt.Sprintf("/%v", sanitized(conf.Version)))
 	versionedGroup.GET("/docs", staticController.Documentation)
+	versionedGroup.GET("/live", staticController.Live)
 	healthGroup := versionedGroup.Group("/health").Use(middleware.ConfigurationMiddleware(conf))
-	namespacedGroup := versionedGroup.Group(fmt.Sprintf("/%v", sanitized(conf.Namespace))).Use(middleware.ConfigurationMiddleware(conf))
+	namespacedGroup := versionedGroup.Group(fmt.Sprintf("/%v", sanitized(conf.Namespace))).Use(
+		middleware.ConfigurationMiddleware(conf),
+		middleware.Authentication(authenticators),
+	)
+
+	read := middleware.RequireRole(config.RoleRead)
+	migrate := middleware.RequireRole(config.RoleMigrate)
+	rollback := middleware.RequireRole(config.RoleRollback)
 
 	{
 		versionedGroup.GET("/", staticController.Ping)
 		healthGroup.GET("", staticController.Health)
-		namespacedGroup.GET("", migrationsController.List)
-		namespacedGroup.POST("", migrationsController.Migrate)
-		namespacedGroup.DELETE("", migrationsController.Rollback)
-		namespacedGroup.GET("/applied", migrationsController.Applied)
-		namespacedGroup.GET("/pending", migrationsController.Pending)
+		namespacedGroup.GET("", read, migrationsController.List)
+		namespacedGroup.POST("", migrate, migrationsController.Migrate)
+		namespacedGroup.DELETE("", rollback, migrationsController.Rollback)
+		namespacedGroup.GET("/applied", read, migrationsController.Applied)
+		namespacedGroup.GET("/pending", read, migrationsController.Pending)
+		namespacedGroup.GET("/history", read, migrationsController.History)
+		namespacedGroup.GET("/jobs/:id", read, migrationsController.Job)
+		namespacedGroup.GET("/events", read, migrationsController.Events)
 	}
 
-	return app
+	return app, nil
 }
 
 func sanitized(value string) string {
@@ -0,0 +1,39 @@
+package services
+
+import "fmt"
+
+// InvalidRequestError - The request is malformed (i.e. an invalid migration name or history filter).
+type InvalidRequestError struct {
+	Reason string
+}
+
+// NotFoundError - The request refers to a migration that does not exist or is not in the expected state.
+type NotFoundError struct {
+	Reason string
+}
+
+// PolicyError - The request is refused by the policy of the database (i.e. allow_rollback: false).
+type PolicyError struct {
+	Reason string
+}
+
+// ConflictError - The request cannot be carried out while another request is in progress (i.e. a running job).
+type ConflictError struct {
+	Reason string
+}
+
+func (error InvalidRequestError) Error() string {
+	return fmt.Sprintf("invalid request: %v", error.Reason)
+}
+
+func (error NotFoundError) Error() string {
+	return error.Reason
+}
+
+func (error PolicyError) Error() string {
+	return error.Reason
+}
+
+func (error ConflictError) Error() string {
+	return error.Reason
+}
@@ -0,0 +1,304 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oleoneto/dm/migrations"
+)
+
+const (
+	JobMigrate  = "migrate"
+	JobRollback = "rollback"
+
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+
+	// MaxJobs - How many jobs are kept. Older jobs are forgotten once they finish.
+	MaxJobs = 100
+
+	// MaxJobEvents - How many events of a job are kept to be replayed to late subscribers.
+	MaxJobEvents = 10000
+
+	// EventJobFinished - Sent to subscribers once a job succeeds or fails. It is the last event of the job.
+	EventJobFinished = "job_finished"
+)
+
+// Job - A migration or rollback run in the background. See `GET /migrations/jobs/:id`.
+type Job struct {
+	ID         string         `json:"id"`
+	Action     string         `json:"action"`
+	Status     string         `json:"status"`
+	Actor      string         `json:"actor,omitempty"`
+	Total      int            `json:"total"`
+	Completed  int            `json:"completed"`
+	Migrations []JobMigration `json:"migrations"`
+	Error      string         `json:"error,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+
+	// events - Replayed to subscribers that arrive after the job started.
+	events []JobEvent
+}
+
+// JobEvent - An event reported by the runner of a job. See `Jobs.Subscribe()`.
+type JobEvent struct {
+	Job string `json:"job"`
+
+	// Status - The status of the job. Only set for `EventJobFinished`.
+	Status string `json:"status,omitempty"`
+
+	migrations.Event
+}
+
+// JobMigration - The progress of one of the migrations of a job.
+type JobMigration struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+
+	// Status - One of pending, running, succeeded, failed, or skipped (i.e. applied by another runner in the meantime).
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+/*
+Jobs:
+
+	Keeps track of the jobs started by the API, in memory.
+	At most one job runs at a time, since every job changes the database.
+
+	The events of jobs are delivered to subscribers as they happen. See `Subscribe()`.
+*/
+type Jobs struct {
+	mutex   sync.Mutex
+	jobs    map[string]*Job
+	order   []string
+	running string
+
+	// subscribers - The channels of subscribers, along with the job they follow (empty for every job).
+	subscribers map[chan JobEvent]string
+
+	// observers - Notified of the events of every job (i.e. to collect metrics). See `Observe()`.
+	observers []func(migrations.Event)
+}
+
+func NewJobs() *Jobs {
+	return &Jobs{jobs: map[string]*Job{}, subscribers: map[chan JobEvent]string{}}
+}
+
+// Find - Returns a snapshot of a job.
+func (jobs *Jobs) Find(id string) (Job, bool) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	job, found := jobs.jobs[id]
+
+	if !found {
+		return Job{}, false
+	}
+
+	return job.snapshot(), true
+}
+
+// Subscribe - Delivers the events of a job (or of every job, when the id is empty) as they happen.
+// The past events of the job are returned to be replayed. The channel is closed once the job finishes.
+// Subscribers that fall behind miss events rather than slowing the job down. Call cancel to unsubscribe.
+func (jobs *Jobs) Subscribe(id string) (replay []JobEvent, events <-chan JobEvent, cancel func(), err error) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	channel := make(chan JobEvent, 256)
+
+	if id != "" {
+		job, found := jobs.jobs[id]
+
+		if !found {
+			return nil, nil, nil, &NotFoundError{Reason: fmt.Sprintf("job '%v' not found", id)}
+		}
+
+		replay = append(replay, job.events...)
+
+		if job.FinishedAt != nil {
+			close(channel)
+			return replay, channel, func() {}, nil
+		}
+	}
+
+	jobs.subscribers[channel] = id
+
+	cancel = func() {
+		jobs.mutex.Lock()
+		defer jobs.mutex.Unlock()
+
+		if _, subscribed := jobs.subscribers[channel]; subscribed {
+			delete(jobs.subscribers, channel)
+			close(channel)
+		}
+	}
+
+	return replay, channel, cancel, nil
+}
+
+// Observe - Notifies the observer of the events of every job, as they happen. Observers must not block.
+func (jobs *Jobs) Observe(observer func(migrations.Event)) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	jobs.observers = append(jobs.observers, observer)
+}
+
+// Start - Runs the work in the background as a new job, unless another job is running.
+// The work reports the progress of each migration through the event handler it is given.
+func (jobs *Jobs) Start(action, actor string, list migrations.Migrations, work func(handler func(migrations.Event)) error) (Job, error) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	if jobs.running != "" {
+		return Job{}, &ConflictError{Reason: fmt.Sprintf("job %v is still running", jobs.running)}
+	}
+
+	job := &Job{
+		ID:         newJobID(),
+		Action:     action,
+		Status:     JobRunning,
+		Actor:      actor,
+		Total:      len(list),
+		Migrations: []JobMigration{},
+		CreatedAt:  time.Now(),
+	}
+
+	for _, migration := range list {
+		job.Migrations = append(job.Migrations, JobMigration{Version: migration.Version, Name: migration.Name, Status: "pending"})
+	}
+
+	jobs.jobs[job.ID] = job
+	jobs.order = append(jobs.order, job.ID)
+	jobs.running = job.ID
+	jobs.forget()
+
+	observers := append([]func(migrations.Event){}, jobs.observers...)
+
+	go func() {
+		err := work(func(event migrations.Event) {
+			jobs.progress(job, event)
+
+			for _, observer := range observers {
+				observer(event)
+			}
+		})
+		jobs.finish(job, err)
+	}()
+
+	return job.snapshot(), nil
+}
+
+// progress - Updates the migration of the job the event is about, and delivers the event to subscribers.
+func (jobs *Jobs) progress(job *Job, event migrations.Event) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	jobs.publish(job, JobEvent{Job: job.ID, Event: event})
+
+	for index := range job.Migrations {
+		migration := &job.Migrations[index]
+
+		if migration.Version != event.Version {
+			continue
+		}
+
+		switch event.Type {
+		case migrations.EventStarted:
+			migration.Status = JobRunning
+		case migrations.EventSucceeded, migrations.EventFailed:
+			migration.Status = event.Type
+			migration.DurationMs = event.DurationMs
+			migration.Error = event.Error
+			job.Completed++
+		}
+	}
+}
+
+func (jobs *Jobs) finish(job *Job, err error) {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Status = JobSucceeded
+
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+	}
+
+	for index := range job.Migrations {
+		if job.Migrations[index].Status == "pending" && err == nil {
+			job.Migrations[index].Status = "skipped"
+		}
+	}
+
+	jobs.running = ""
+
+	jobs.publish(job, JobEvent{Job: job.ID, Status: job.Status, Event: migrations.Event{Type: EventJobFinished, Error: job.Error, Time: finished}})
+
+	for channel, id := range jobs.subscribers {
+		if id == job.ID {
+			delete(jobs.subscribers, channel)
+			close(channel)
+		}
+	}
+}
+
+// publish - Records an event of a job and delivers it to subscribers. Expects the mutex to be locked.
+func (jobs *Jobs) publish(job *Job, event JobEvent) {
+	if len(job.events) < MaxJobEvents {
+		job.events = append(job.events, event)
+	}
+
+	for channel, id := range jobs.subscribers {
+		if id != "" && id != job.ID {
+			continue
+		}
+
+		select {
+		case channel <- event:
+		default:
+		}
+	}
+}
+
+// forget - Removes the oldest finished jobs once there are more than `MaxJobs`.
+func (jobs *Jobs) forget() {
+	kept := []string{}
+
+	for index, id := range jobs.order {
+		if len(jobs.order)-index > MaxJobs && id != jobs.running {
+			delete(jobs.jobs, id)
+			continue
+		}
+
+		kept = append(kept, id)
+	}
+
+	jobs.order = kept
+}
+
+func (job *Job) snapshot() Job {
+	snapshot := *job
+	snapshot.Migrations = append([]JobMigration{}, job.Migrations...)
+	snapshot.events = nil
+
+	return snapshot
+}
+
+func newJobID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
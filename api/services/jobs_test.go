@@ -0,0 +1,293 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/dm/migrations"
+)
+
+func testMigrations() migrations.Migrations {
+	return migrations.Migrations{
+		{Version: "20221231054530129328", Name: "CreateUsers"},
+		{Version: "20221231054531293821", Name: "CreateArticles"},
+	}
+}
+
+// succeed - Work that applies each migration it is given.
+func succeed(list migrations.Migrations) func(func(migrations.Event)) error {
+	return func(handler func(migrations.Event)) error {
+		for _, migration := range list {
+			handler(migrations.Event{Type: migrations.EventStarted, Direction: "up", Version: migration.Version, Name: migration.Name})
+			handler(migrations.Event{Type: migrations.EventSucceeded, Direction: "up", Version: migration.Version, Name: migration.Name, DurationMs: 5})
+		}
+
+		return nil
+	}
+}
+
+// waitFor - Returns the job once it finishes.
+func waitFor(t *testing.T, jobs *Jobs, id string) Job {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if job, _ := jobs.Find(id); job.FinishedAt != nil {
+			return job
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf(`expected job %v to finish`, id)
+	return Job{}
+}
+
+// ----------------------------------
+
+func TestJobsStart(t *testing.T) {
+	jobs := NewJobs()
+	list := testMigrations()
+
+	// Scenario 1: Jobs start as running, with every migration pending
+	release := make(chan struct{})
+
+	job, err := jobs.Start(JobMigrate, "alice", list, func(handler func(migrations.Event)) error {
+		<-release
+		return succeed(list)(handler)
+	})
+
+	if err != nil || job.Status != JobRunning || job.Total != 2 || job.Actor != "alice" || job.Migrations[0].Status != "pending" {
+		t.Fatalf(`expected a running job, but got %+v (%v)`, job, err)
+	}
+
+	// Scenario 2: Only one job runs at a time
+	if _, err = jobs.Start(JobRollback, "bob", list, nothing); !errors.As(err, new(*ConflictError)) {
+		t.Errorf(`expected a conflict, but got %v`, err)
+	}
+
+	// Scenario 3: Jobs succeed once their work is done
+	close(release)
+	job = waitFor(t, jobs, job.ID)
+
+	if job.Status != JobSucceeded || job.Completed != 2 || job.Error != "" {
+		t.Errorf(`expected the job to succeed, but got %+v`, job)
+	}
+
+	for _, migration := range job.Migrations {
+		if migration.Status != JobSucceeded || migration.DurationMs != 5 {
+			t.Errorf(`expected %v to succeed, but got %+v`, migration.Name, migration)
+		}
+	}
+
+	// Scenario 4: Another job can start once the previous one finished
+	if _, err = jobs.Start(JobRollback, "bob", migrations.Migrations{}, nothing); err != nil {
+		t.Errorf(`expected the job to start, but got %v`, err)
+	}
+}
+
+func TestJobsFailure(t *testing.T) {
+	jobs := NewJobs()
+	list := testMigrations()
+
+	job, _ := jobs.Start(JobMigrate, "", list, func(handler func(migrations.Event)) error {
+		handler(migrations.Event{Type: migrations.EventStarted, Version: list[0].Version})
+		handler(migrations.Event{Type: migrations.EventFailed, Version: list[0].Version, Error: "syntax error"})
+
+		return errors.New("migration failed")
+	})
+
+	job = waitFor(t, jobs, job.ID)
+
+	if job.Status != JobFailed || job.Error != "migration failed" || job.Completed != 1 {
+		t.Errorf(`expected the job to fail, but got %+v`, job)
+	}
+
+	if job.Migrations[0].Status != JobFailed || job.Migrations[0].Error != "syntax error" {
+		t.Errorf(`expected the failed migration to be reported, but got %+v`, job.Migrations[0])
+	}
+
+	// NOTE: Migrations that were not run remain pending, since the job did not complete
+	if job.Migrations[1].Status != "pending" {
+		t.Errorf(`expected the remaining migration to be pending, but got %+v`, job.Migrations[1])
+	}
+}
+
+func TestJobsSkipped(t *testing.T) {
+	jobs := NewJobs()
+	list := testMigrations()
+
+	// NOTE: Another runner applied the second migration in the meantime
+	job, _ := jobs.Start(JobMigrate, "", list, succeed(list[:1]))
+	job = waitFor(t, jobs, job.ID)
+
+	if job.Status != JobSucceeded || job.Migrations[0].Status != JobSucceeded || job.Migrations[1].Status != "skipped" {
+		t.Errorf(`expected the second migration to be skipped, but got %+v`, job.Migrations)
+	}
+}
+
+func TestJobsSnapshot(t *testing.T) {
+	jobs := NewJobs()
+	list := testMigrations()
+
+	job, _ := jobs.Start(JobMigrate, "", list, succeed(list))
+	job.Migrations[0].Status = "tampered"
+	job.Status = "tampered"
+
+	found, ok := jobs.Find(job.ID)
+
+	if !ok || found.Status == "tampered" || found.Migrations[0].Status == "tampered" {
+		t.Errorf(`expected the job not to be modified through its snapshot, but got %+v`, found)
+	}
+
+	waitFor(t, jobs, job.ID)
+}
+
+func TestJobsForget(t *testing.T) {
+	jobs := NewJobs()
+	ids := []string{}
+
+	for index := 0; index < MaxJobs+5; index++ {
+		job, err := jobs.Start(JobMigrate, fmt.Sprint(index), migrations.Migrations{}, nothing)
+
+		if err != nil {
+			t.Fatalf(`expected job %v to start, but got %v`, index, err)
+		}
+
+		waitFor(t, jobs, job.ID)
+		ids = append(ids, job.ID)
+	}
+
+	// NOTE: The job that pushes the count over the limit is started before the oldest jobs are forgotten
+	for index, id := range ids {
+		_, found := jobs.Find(id)
+
+		if kept := index > len(ids)-MaxJobs-1; found != kept {
+			t.Errorf(`expected job %v to be kept == %v, but got %v`, index, kept, found)
+		}
+	}
+
+	if _, found := jobs.Find("unknown"); found {
+		t.Errorf(`expected unknown jobs not to be found`)
+	}
+}
+
+func TestJobsObserve(t *testing.T) {
+	jobs := NewJobs()
+	list := testMigrations()
+
+	observed := make(chan migrations.Event, 10)
+	jobs.Observe(func(event migrations.Event) { observed <- event })
+
+	job, _ := jobs.Start(JobMigrate, "", list, succeed(list))
+	waitFor(t, jobs, job.ID)
+
+	if len(observed) != 4 {
+		t.Errorf(`expected every event to be observed, but got %v`, len(observed))
+	}
+}
+
+func TestJobsSubscribe(t *testing.T) {
+	jobs := NewJobs()
+	list := testMigrations()
+	release := make(chan struct{})
+
+	job, _ := jobs.Start(JobMigrate, "", list, func(handler func(migrations.Event)) error {
+		<-release
+		return succeed(list)(handler)
+	})
+
+	// Scenario 1: Events are delivered as they happen, and the channel is closed once the job finishes
+	replay, events, cancel, err := jobs.Subscribe(job.ID)
+
+	if err != nil || len(replay) != 0 {
+		t.Fatalf(`expected to subscribe without a replay, but got %v (%v)`, replay, err)
+	}
+
+	defer cancel()
+
+	close(release)
+
+	received := []string{}
+
+	for event := range events {
+		received = append(received, event.Type)
+	}
+
+	expected := "started,succeeded,started,succeeded,job_finished"
+
+	if strings.Join(received, ",") != expected {
+		t.Errorf(`expected events %v, but got %v`, expected, strings.Join(received, ","))
+	}
+
+	// Scenario 2: Subscribers of a finished job are sent its past events, and a closed channel
+	replay, events, _, err = jobs.Subscribe(job.ID)
+
+	if _, open := <-events; err != nil || open || len(replay) != 5 || replay[4].Status != JobSucceeded {
+		t.Errorf(`expected the events of the job to be replayed, but got %v (%v)`, replay, err)
+	}
+
+	// Scenario 3: Unknown jobs cannot be followed
+	if _, _, _, err = jobs.Subscribe("unknown"); !errors.As(err, new(*NotFoundError)) {
+		t.Errorf(`expected %T, but got %v`, new(*NotFoundError), err)
+	}
+
+	if count := subscribers(jobs); count != 0 {
+		t.Errorf(`expected no subscribers to be left, but got %v`, count)
+	}
+}
+
+func TestJobsUnsubscribe(t *testing.T) {
+	jobs := NewJobs()
+
+	_, events, cancel, _ := jobs.Subscribe("")
+
+	// NOTE: Subscribers of every job remain subscribed until they cancel
+	job, _ := jobs.Start(JobMigrate, "", migrations.Migrations{}, nothing)
+	waitFor(t, jobs, job.ID)
+
+	if event := <-events; event.Type != EventJobFinished || event.Job != job.ID {
+		t.Errorf(`expected the job to finish, but got %+v`, event)
+	}
+
+	cancel()
+	cancel()
+
+	if _, open := <-events; open || subscribers(jobs) != 0 {
+		t.Errorf(`expected the subscriber to be removed and its channel closed`)
+	}
+
+	// NOTE: Jobs started after a subscriber left are not affected by it
+	job, _ = jobs.Start(JobMigrate, "", migrations.Migrations{}, nothing)
+	waitFor(t, jobs, job.ID)
+}
+
+func TestJobsSlowSubscriber(t *testing.T) {
+	jobs := NewJobs()
+	list := testMigrations()
+
+	// NOTE: The subscriber never reads its events
+	_, _, cancel, _ := jobs.Subscribe("")
+	defer cancel()
+
+	job, _ := jobs.Start(JobMigrate, "", list, func(handler func(migrations.Event)) error {
+		for index := 0; index < 1000; index++ {
+			handler(migrations.Event{Type: migrations.EventStatement, Version: list[0].Version})
+		}
+
+		return nil
+	})
+
+	if job = waitFor(t, jobs, job.ID); job.Status != JobSucceeded {
+		t.Errorf(`expected the job to succeed, but got %+v`, job)
+	}
+}
+
+func subscribers(jobs *Jobs) int {
+	jobs.mutex.Lock()
+	defer jobs.mutex.Unlock()
+
+	return len(jobs.subscribers)
+}
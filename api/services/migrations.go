@@ -0,0 +1,403 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/migrations"
+)
+
+var (
+	namePattern    = regexp.MustCompile(`^[a-zA-Z]+(\_?[a-zA-Z])*$`)
+	versionPattern = regexp.MustCompile(`^\d{20}$`)
+
+	// timeLayouts - Layouts accepted by the `since` and `until` history filters.
+	timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+)
+
+/*
+MigrationService:
+
+	Runs migrations on behalf of API requests.
+
+	Every call uses a runner of its own, so requests can be served concurrently.
+	Migrations and rollbacks are validated right away, then run in the background as jobs. See `Jobs`.
+*/
+type MigrationService struct {
+	config      config.APIConfig
+	filePattern regexp.Regexp
+	jobs        *Jobs
+}
+
+// MigrateRequest - Selects the pending migrations to apply. The zero value selects every pending migration.
+type MigrateRequest struct {
+	// Migration - The name or version of the last migration to apply.
+	Migration string
+
+	// AcceptDrift - Records the new checksums of modified migration files, instead of refusing to run. See `migrations.Runner.SetAcceptDrift()`.
+	AcceptDrift bool
+}
+
+// RollbackRequest - Selects the applied migrations to rollback. The zero value selects every applied migration.
+type RollbackRequest struct {
+	// Migration - The name or version of the oldest migration to rollback.
+	Migration string
+	Batch     int
+	LastBatch bool
+}
+
+// HistoryQuery - The unparsed filters of a history request. See `migrations.HistoryFilter`.
+type HistoryQuery struct {
+	Migration string
+	Action    string
+	Since     string
+	Until     string
+	Limit     string
+}
+
+func NewMigrationService(conf config.APIConfig) *MigrationService {
+	return &MigrationService{config: conf, filePattern: migrations.FilePattern, jobs: NewJobs()}
+}
+
+// WithActor - Returns a service that records the actor (i.e. the caller of a request) along with the changes it makes.
+func (service *MigrationService) WithActor(actor string) *MigrationService {
+	scoped := *service
+
+	if actor != "" {
+		scoped.config.Actor = actor
+	}
+
+	return &scoped
+}
+
+// MARK: - Stateless Operations
+
+// All - Lists the migration files.
+func (service *MigrationService) All() migrations.Migrations {
+	list := migrations.LoadMigrations(service.config.Directories, &service.filePattern)
+	return list.ToSlice()
+}
+
+// Applied - Lists the applied migrations. Migrations modified since they were applied are marked as drifted.
+// Returns an error if the database cannot be read.
+func (service *MigrationService) Applied() (migrations.Migrations, error) {
+	if err := service.Ping(); err != nil {
+		return migrations.Migrations{}, err
+	}
+
+	loadFromDir := true
+	list := service.runner().AppliedMigrations(service.config.Directories, &service.filePattern, loadFromDir)
+	return list.ToSlice(), nil
+}
+
+// Pending - Lists the migrations that have yet to be applied. Returns an error if the database cannot be read.
+func (service *MigrationService) Pending() (migrations.Migrations, error) {
+	if err := service.Ping(); err != nil {
+		return migrations.Migrations{}, err
+	}
+
+	list := service.runner().PendingMigrations(service.config.Directories, &service.filePattern)
+	return list.ToSlice(), nil
+}
+
+// Ping - Returns an error if the database cannot be reached.
+// The runner treats unreadable databases as empty, so they are checked before they are read.
+func (service *MigrationService) Ping() error {
+	if err := service.config.Store.Read("SELECT 1;", &[]int{}); err != nil {
+		return fmt.Errorf("unable to reach the database: %w", err)
+	}
+
+	return nil
+}
+
+// History - Lists the history of the database, most recent first.
+func (service *MigrationService) History(query HistoryQuery) (migrations.History, error) {
+	filter, err := parsedHistoryQuery(query)
+
+	if err != nil {
+		return migrations.History{}, err
+	}
+
+	return service.runner().History(filter)
+}
+
+// Job - Returns the progress of a job started by `Migrate()` or `Rollback()`.
+func (service *MigrationService) Job(id string) (Job, error) {
+	job, found := service.jobs.Find(id)
+
+	if !found {
+		return job, &NotFoundError{Reason: fmt.Sprintf("job '%v' not found", id)}
+	}
+
+	return job, nil
+}
+
+// Events - Streams the events of a job, or of every job when the id is empty. See `Jobs.Subscribe()`.
+func (service *MigrationService) Events(id string) ([]JobEvent, <-chan JobEvent, func(), error) {
+	return service.jobs.Subscribe(id)
+}
+
+// Observe - Notifies the observer of the events of every migration and rollback started by the service. See `Jobs.Observe()`.
+func (service *MigrationService) Observe(observer func(migrations.Event)) {
+	service.jobs.Observe(observer)
+}
+
+// MARK: - Stateful Operations
+
+// Migrate - Starts a job that applies the pending migrations, or the pending migrations up to the target.
+func (service *MigrationService) Migrate(request MigrateRequest) (Job, error) {
+	identifier, err := parsedTarget(request.Migration)
+
+	if err != nil {
+		return Job{}, err
+	}
+
+	runner := service.runner()
+	runner.SetAcceptDrift(request.AcceptDrift)
+
+	list := runner.PendingMigrations(service.config.Directories, &service.filePattern)
+
+	if identifier != "" {
+		sequence, found := list.Find(identifier)
+
+		if found {
+			list = sequence
+		} else {
+			all := migrations.LoadMigrations(service.config.Directories, &service.filePattern)
+
+			if _, exists := all.Find(identifier); !exists {
+				return Job{}, &NotFoundError{Reason: fmt.Sprintf("migration '%v' not found", request.Migration)}
+			}
+
+			// NOTE: The migration is already applied
+			list = migrations.MigrationList{}
+		}
+	}
+
+	if err = service.refuseDestructive(list); err != nil {
+		return Job{}, err
+	}
+
+	if valid, reason := migrations.Validate(list); !valid {
+		return Job{}, fmt.Errorf("%w: %v", new(migrations.ValidationError), reason)
+	}
+
+	// NOTE: Dirty and modified migrations are refused right away, rather than in the job
+	if err = runner.Check(list); err != nil {
+		return Job{}, err
+	}
+
+	return service.jobs.Start(JobMigrate, service.config.Actor, list.ToSlice(), func(handler func(migrations.Event)) error {
+		runner.SetEventHandler(handler)
+		return runner.Up(list)
+	})
+}
+
+// Rollback - Starts a job that reverts the applied migrations selected by the request, most recent first.
+func (service *MigrationService) Rollback(request RollbackRequest) (Job, error) {
+	identifier, err := parsedTarget(request.Migration)
+
+	if err != nil {
+		return Job{}, err
+	}
+
+	if request.Batch < 0 {
+		return Job{}, &InvalidRequestError{Reason: "batch must be a positive number"}
+	}
+
+	if identifier != "" && (request.Batch != 0 || request.LastBatch) {
+		return Job{}, &InvalidRequestError{Reason: "migration cannot be combined with batch or last_batch"}
+	}
+
+	if err = service.refuseRollback(); err != nil {
+		return Job{}, err
+	}
+
+	runner := service.runner()
+
+	loadFromDir := true
+	list := runner.AppliedMigrations(service.config.Directories, &service.filePattern, loadFromDir)
+
+	batch := request.Batch
+
+	if request.LastBatch {
+		batch = runner.LastBatch()
+
+		if batch == 0 {
+			return service.jobs.Start(JobRollback, service.config.Actor, migrations.Migrations{}, nothing)
+		}
+	}
+
+	// NOTE: Later batches are rolled back as well. This is done to ensure schema consistency.
+	if batch > 0 {
+		list = list.SinceBatch(batch)
+	}
+
+	if list.Size() == 0 {
+		return service.jobs.Start(JobRollback, service.config.Actor, migrations.Migrations{}, nothing)
+	}
+
+	list.Reverse()
+
+	if identifier != "" {
+		sequence, found := list.Find(identifier)
+
+		if !found {
+			return Job{}, &NotFoundError{Reason: fmt.Sprintf("migration '%v' is not applied", request.Migration)}
+		}
+
+		list = sequence
+	}
+
+	if valid, reason := migrations.Validate(list); !valid {
+		return Job{}, fmt.Errorf("%w: %v", new(migrations.ValidationError), reason)
+	}
+
+	return service.jobs.Start(JobRollback, service.config.Actor, list.ToSlice(), func(handler func(migrations.Event)) error {
+		runner.SetEventHandler(handler)
+		return runner.Down(list)
+	})
+}
+
+// MARK: - Helpers
+
+// runner - Returns a runner configured for the database served by the API.
+func (service *MigrationService) runner() *migrations.Runner {
+	runner := &migrations.Runner{}
+	runner.SetStore(service.config.Store)
+	runner.SetSchemaTable(service.config.Table)
+	runner.SetLogger("plain", "")
+	runner.SetLockTimeout(service.config.LockTimeout)
+	runner.SetActor(service.config.Actor)
+	runner.SetSource(migrations.SourceAPI)
+	runner.SetToolVersion(service.config.ToolVersion)
+
+	return runner
+}
+
+// nothing - The work of jobs that have nothing to do.
+func nothing(handler func(migrations.Event)) error {
+	return nil
+}
+
+// refuseRollback - Returns an error if the policy of the database does not permit rollbacks.
+func (service *MigrationService) refuseRollback() error {
+	if !service.config.Policy.RollbackAllowed() {
+		return &PolicyError{Reason: fmt.Sprintf("rollbacks are not allowed on %v", service.target())}
+	}
+
+	pattern, protected, err := service.config.Policy.ProtectedBy(service.config.ConnectionString)
+
+	if err != nil {
+		return fmt.Errorf("invalid policy: %w", err)
+	}
+
+	if protected {
+		return &PolicyError{Reason: fmt.Sprintf("rollbacks are not allowed on %v (protected by '%v')", service.target(), pattern)}
+	}
+
+	return nil
+}
+
+// refuseDestructive - Returns an error if the migrations drop or truncate tables and the policy of the database refuses them.
+func (service *MigrationService) refuseDestructive(list migrations.MigrationList) error {
+	if !service.config.Policy.RefuseDestructive {
+		return nil
+	}
+
+	destructive := []string{}
+
+	for curr := list.GetHead(); curr != nil; curr = curr.Next() {
+		if statements := curr.Destructive(); len(statements) != 0 {
+			destructive = append(destructive, fmt.Sprintf("%v (%v)", curr.Version, strings.Join(statements, ", ")))
+		}
+	}
+
+	if len(destructive) != 0 {
+		return &PolicyError{Reason: fmt.Sprintf("migrations that destroy data are not allowed on %v: %v", service.target(), strings.Join(destructive, "; "))}
+	}
+
+	return nil
+}
+
+// target - Describes the database served by the API.
+func (service *MigrationService) target() string {
+	if service.config.Environment == "" {
+		return config.RedactURL(service.config.ConnectionString)
+	}
+
+	return fmt.Sprintf("environment '%v'", service.config.Environment)
+}
+
+// parsedTarget - Validates the name or version of a migration. Names are converted to the name of the migration type (i.e. CreateItems).
+func parsedTarget(target string) (string, error) {
+	switch {
+	case target == "":
+		return "", nil
+	case versionPattern.MatchString(target):
+		return target, nil
+	case namePattern.MatchString(target):
+		return strcase.ToCamel(target), nil
+	default:
+		return "", &InvalidRequestError{Reason: fmt.Sprintf("invalid migration version or name '%v'", target)}
+	}
+}
+
+func parsedHistoryQuery(query HistoryQuery) (migrations.HistoryFilter, error) {
+	filter := migrations.HistoryFilter{Limit: migrations.DefaultHistoryEntries}
+
+	var err error
+
+	if filter.Migration, err = parsedTarget(query.Migration); err != nil {
+		return filter, err
+	}
+
+	if query.Action != "" {
+		known := false
+
+		for _, action := range migrations.HistoryActions {
+			known = known || action == query.Action
+		}
+
+		if !known {
+			return filter, &InvalidRequestError{Reason: fmt.Sprintf("action must be one of: %v", strings.Join(migrations.HistoryActions, ", "))}
+		}
+
+		filter.Action = query.Action
+	}
+
+	if filter.Since, err = parsedTime("since", query.Since); err != nil {
+		return filter, err
+	}
+
+	if filter.Until, err = parsedTime("until", query.Until); err != nil {
+		return filter, err
+	}
+
+	if query.Limit != "" {
+		if filter.Limit, err = strconv.Atoi(query.Limit); err != nil || filter.Limit <= 0 {
+			return filter, &InvalidRequestError{Reason: "limit must be a positive number"}
+		}
+	}
+
+	return filter, nil
+}
+
+func parsedTime(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, &InvalidRequestError{Reason: fmt.Sprintf("invalid %v '%v' (expected RFC3339 or 2006-01-02)", name, value)}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/migrations"
+	"github.com/oleoneto/dm/stores"
+)
+
+const (
+	createItems = "20221231054530129328_create_items.sql"
+	createTags  = "20221231054531293821_create_tags.sql"
+)
+
+// testService - A service for an empty SQLite database, with the given migration files.
+func testService(t *testing.T, files map[string]string) (*MigrationService, string) {
+	directory := t.TempDir()
+
+	for name, contents := range files {
+		writeMigration(t, directory, name, contents)
+	}
+
+	database := filepath.Join(t.TempDir(), "dm.sqlite")
+
+	return NewMigrationService(config.APIConfig{
+		Adapter:          "sqlite3",
+		ConnectionString: database,
+		Directories:      []string{directory},
+		Table:            "_migrations",
+		Store:            stores.SQLite3{URL: database},
+		LockTimeout:      500 * time.Millisecond,
+	}), directory
+}
+
+func writeMigration(t *testing.T, directory, name, contents string) {
+	if err := os.WriteFile(filepath.Join(directory, name), []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sqlMigration(table string) string {
+	return "-- +dm Engine sqlite3\n\n-- +dm Up\nCREATE TABLE " + table + " (id INTEGER);\n\n-- +dm Down\nDROP TABLE " + table + ";\n"
+}
+
+// migrate - Applies the migrations selected by the request, failing the test if the job does not succeed.
+func migrate(t *testing.T, service *MigrationService, request MigrateRequest) Job {
+	job, err := service.Migrate(request)
+
+	if err != nil {
+		t.Fatalf(`expected the migrations to start, but got %v`, err)
+	}
+
+	if job = waitFor(t, service.jobs, job.ID); job.Status != JobSucceeded {
+		t.Fatalf(`expected the migrations to succeed, but got %+v`, job)
+	}
+
+	return job
+}
+
+// ----------------------------------
+
+func TestMigrationServiceMigrate(t *testing.T) {
+	service, _ := testService(t, map[string]string{createItems: sqlMigration("items"), createTags: sqlMigration("tags")})
+
+	// Scenario 1: Migrations are applied up to the target
+	if job := migrate(t, service, MigrateRequest{Migration: "create_items"}); job.Total != 1 {
+		t.Errorf(`expected 1 migration, but got %v`, job.Total)
+	}
+
+	// Scenario 2: Nothing is applied when the target is already applied
+	if job := migrate(t, service, MigrateRequest{Migration: "create_items"}); job.Total != 0 {
+		t.Errorf(`expected no migrations, but got %+v`, job.Migrations)
+	}
+
+	// Scenario 3: The remaining migrations are applied
+	if job := migrate(t, service, MigrateRequest{}); job.Total != 1 || job.Migrations[0].Name != "CreateTags" {
+		t.Errorf(`expected CreateTags to be applied, but got %+v`, job.Migrations)
+	}
+
+	if applied, _ := service.Applied(); len(applied) != 2 || applied[0].Source != migrations.SourceAPI {
+		t.Errorf(`expected 2 migrations applied by the API, but got %+v`, applied)
+	}
+}
+
+func TestMigrationServiceErrors(t *testing.T) {
+	service, _ := testService(t, map[string]string{createItems: sqlMigration("items")})
+
+	scenarios := []struct {
+		description string
+		call        func() error
+		expected    interface{}
+	}{
+		{"invalid migration", func() error { _, err := service.Migrate(MigrateRequest{Migration: "drop items;"}); return err }, new(*InvalidRequestError)},
+		{"unknown migration", func() error { _, err := service.Migrate(MigrateRequest{Migration: "create_users"}); return err }, new(*NotFoundError)},
+		{"unknown job", func() error { _, err := service.Job("unknown"); return err }, new(*NotFoundError)},
+		{"invalid batch", func() error { _, err := service.Rollback(RollbackRequest{Batch: -1}); return err }, new(*InvalidRequestError)},
+		{"migration and batch", func() error {
+			_, err := service.Rollback(RollbackRequest{Migration: "create_items", LastBatch: true})
+			return err
+		}, new(*InvalidRequestError)},
+		{"invalid history filter", func() error { _, err := service.History(HistoryQuery{Action: "dropped"}); return err }, new(*InvalidRequestError)},
+	}
+
+	for _, scenario := range scenarios {
+		if err := scenario.call(); !errors.As(err, scenario.expected) {
+			t.Errorf(`%v: expected %T, but got %v`, scenario.description, scenario.expected, err)
+		}
+	}
+
+	// NOTE: Rollbacks of migrations that are not applied are refused
+	migrate(t, service, MigrateRequest{})
+	writeMigration(t, service.config.Directories[0], createTags, sqlMigration("tags"))
+
+	if _, err := service.Rollback(RollbackRequest{Migration: "create_tags"}); !errors.As(err, new(*NotFoundError)) {
+		t.Errorf(`expected %T, but got %v`, new(*NotFoundError), err)
+	}
+}
+
+func TestMigrationServicePolicy(t *testing.T) {
+	service, _ := testService(t, map[string]string{createItems: sqlMigration("items")})
+	migrate(t, service, MigrateRequest{})
+
+	allowed := false
+	service.config.Policy = config.Policy{AllowRollback: &allowed, RefuseDestructive: true}
+
+	if _, err := service.Rollback(RollbackRequest{}); !errors.As(err, new(*PolicyError)) {
+		t.Errorf(`expected %T, but got %v`, new(*PolicyError), err)
+	}
+
+	// NOTE: Invalid protected patterns are reported rather than crashing the server
+	service.config.Policy = config.Policy{Protected: []string{"prod("}, RefuseDestructive: true}
+
+	if _, err := service.Rollback(RollbackRequest{}); err == nil || errors.As(err, new(*PolicyError)) || !strings.Contains(err.Error(), "invalid policy") {
+		t.Errorf(`expected the policy to be invalid, but got %v`, err)
+	}
+
+	writeMigration(t, service.config.Directories[0], createTags, "-- +dm Engine sqlite3\n\n-- +dm Up\nDROP TABLE items;\n\n-- +dm Down\nCREATE TABLE items (id INTEGER);\n")
+
+	if _, err := service.Migrate(MigrateRequest{}); !errors.As(err, new(*PolicyError)) {
+		t.Errorf(`expected %T, but got %v`, new(*PolicyError), err)
+	}
+}
+
+func TestMigrationServiceLocked(t *testing.T) {
+	service, _ := testService(t, map[string]string{createItems: sqlMigration("items")})
+	store := service.config.Store
+
+	// NOTE: Another runner holds the migration lock
+	_ = store.Create(migrations.CreateLockTable(service.config.Table))
+	_ = store.Create(migrations.CreateLockEntry(migrations.SQLite3Dialect, service.config.Table), "bob", "web-1", 42)
+
+	job, err := service.Migrate(MigrateRequest{})
+
+	if err != nil {
+		t.Fatalf(`expected the job to start, but got %v`, err)
+	}
+
+	// Scenario 1: Requests are refused while a job is waiting for the lock
+	if _, err = service.Migrate(MigrateRequest{}); !errors.As(err, new(*ConflictError)) {
+		t.Errorf(`expected %T, but got %v`, new(*ConflictError), err)
+	}
+
+	if _, err = service.Rollback(RollbackRequest{}); !errors.As(err, new(*ConflictError)) {
+		t.Errorf(`expected %T, but got %v`, new(*ConflictError), err)
+	}
+
+	// Scenario 2: The job fails once the lock could not be acquired in time, naming the runner holding it
+	if job = waitFor(t, service.jobs, job.ID); job.Status != JobFailed || !strings.HasPrefix(job.Error, "unable to acquire migration lock held by bob@web-1 (pid 42) since ") {
+		t.Errorf(`expected the job to fail with a lock error, but got %+v`, job)
+	}
+}
+
+func TestMigrationServiceDirty(t *testing.T) {
+	service, _ := testService(t, map[string]string{createItems: sqlMigration("items")})
+	migrate(t, service, MigrateRequest{})
+
+	applied, _ := service.Applied()
+	version := applied[0].Version
+	store := service.config.Store
+
+	if err := store.Create(migrations.MarkMigrationDirty(migrations.SQLite3Dialect, service.config.Table), 0, version); err != nil {
+		t.Fatal(err)
+	}
+
+	writeMigration(t, service.config.Directories[0], createTags, sqlMigration("tags"))
+
+	if _, err := service.Migrate(MigrateRequest{}); !errors.As(err, new(*migrations.DirtyError)) {
+		t.Errorf(`expected %T, but got %v`, new(*migrations.DirtyError), err)
+	}
+}
+
+func TestMigrationServiceDrift(t *testing.T) {
+	service, directory := testService(t, map[string]string{createItems: sqlMigration("items")})
+	migrate(t, service, MigrateRequest{})
+
+	// NOTE: The applied migration was modified
+	writeMigration(t, directory, createItems, sqlMigration("products"))
+	writeMigration(t, directory, createTags, sqlMigration("tags"))
+
+	// Scenario 1: Modified migrations are refused before a job starts
+	_, err := service.Migrate(MigrateRequest{})
+
+	var mismatch *migrations.ChecksumMismatchError
+
+	if !errors.As(err, &mismatch) || len(mismatch.Versions) != 1 || mismatch.Versions[0] != "20221231054530129328" {
+		t.Errorf(`expected a checksum mismatch of 20221231054530129328, but got %v`, err)
+	}
+
+	// Scenario 2: The new checksums are recorded when the drift is accepted
+	migrate(t, service, MigrateRequest{AcceptDrift: true})
+
+	applied, _ := service.Applied()
+
+	for _, migration := range applied {
+		if migration.Drifted {
+			t.Errorf(`expected %v not to be drifted`, migration.Name)
+		}
+	}
+}
+
+func TestMigrationServiceUnreachable(t *testing.T) {
+	service, _ := testService(t, map[string]string{createItems: sqlMigration("items")})
+	database := filepath.Join(t.TempDir(), "missing", "dm.sqlite")
+	service.config.Store = stores.SQLite3{URL: database}
+
+	// Scenario 1: Pending migrations are not listed for a database that cannot be read
+	if pending, err := service.Pending(); err == nil {
+		t.Errorf(`expected an error, but got %+v`, pending)
+	}
+
+	// Scenario 2: Neither are applied migrations
+	if applied, err := service.Applied(); err == nil {
+		t.Errorf(`expected an error, but got %+v`, applied)
+	}
+}
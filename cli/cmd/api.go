@@ -23,24 +23,42 @@ var (
 		Short: "Run a RESTful API",
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			validateDatabaseConfig()
-			overrideVariablesFromEnvironment()
 
 			apiConfig = c.APIConfig{
+				Adapter:          adapter,
 				AllowedHost:      apiHost,
 				ConnectionString: databaseUrl,
 				DebugMode:        apiDebugMode,
-				Directory:        directory,
+				Directories:      directories,
+				Environment:      environment,
 				Namespace:        apiNamespacePrefix,
 				Table:            table,
 				Version:          apiVersionPrefix,
+				Store:            storeAdapter,
+				Policy:           configFile.Policy,
+				LockTimeout:      lockTimeout,
+				Actor:            actor,
+				ToolVersion:      version.Version,
+				Auth:             configFile.API.Auth,
 			}
 		},
 		PreRun: func(cmd *cobra.Command, args []string) {
+			if !apiConfig.Auth.Enabled() {
+				fmt.Println("⚠️  No API credentials configured. Requests are not authenticated.")
+			}
+
 			fmt.Println("⚡️ Server running on port", serverPort)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			app := server.API(apiConfig)
-			err := app.Run(fmt.Sprintf(":%v", serverPort))
+			app, err := server.API(apiConfig)
+
+			if err != nil {
+				message := ErrorOutput{Error: err.Error()}
+				logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			err = app.Run(fmt.Sprintf(":%v", serverPort))
 
 			if err != nil {
 				message := ErrorOutput{Error: err.Error()}
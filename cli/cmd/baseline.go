@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iancoleman/strcase"
+	"github.com/oleoneto/dm/logger"
+	"github.com/spf13/cobra"
+)
+
+var (
+	baselineCmd = &cobra.Command{
+		Use:   "baseline NAME|VERSION",
+		Short: "Mark migration(s) as applied without running them",
+		Long: `Mark migration(s) as applied without running them.
+
+The given migration and every migration that comes before it are recorded as applied (and flagged as baselined).
+Use this command to adopt dm on a database whose schema already includes the changes of these migrations.`,
+		Args: cobra.ExactArgs(1),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			validateDatabaseConfig()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			version, err := parsedVersionFlag(args[0])
+
+			if err != nil {
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			list := runner.PendingMigrations(directories, &FilePattern)
+			sequence, found := list.Find(strcase.ToCamel(version.Value))
+
+			if !found {
+				message := logger.ApplicationMessage{Message: "Nothing to do."}
+				logger.Custom(format, template).WithFormattedOutput(&message, os.Stdout)
+				return
+			}
+
+			if err = confirmAction(fmt.Sprintf("Mark %v migration(s) as applied", sequence.Size()), sequence.ToSlice(), false); err != nil {
+				refuse(err)
+			}
+
+			if err = runner.Baseline(sequence); err != nil {
+				os.Exit(exitCode(err))
+			}
+		},
+	}
+)
+
+func init() {
+	baselineCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url")
+	baselineCmd.MarkFlagRequired("database-url")
+	baselineCmd.MarkFlagRequired("adapter")
+	baselineCmd.MarkFlagRequired("table")
+}
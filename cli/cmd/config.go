@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	c "github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/logger"
+	"github.com/oleoneto/dm/migrations"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	configFile = c.File{}
+	lintRules  = migrations.LintRules{}
+
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	showConfigCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Shows the effective configuration (secrets are redacted)",
+		Run: func(cmd *cobra.Command, args []string) {
+			effective := c.File{
+				Path:           configFile.Path,
+				Adapter:        adapter,
+				DatabaseURL:    databaseUrl,
+				Directory:      directories,
+				Table:          table,
+				OutputFormat:   format,
+				OutputTemplate: template,
+				API: c.APISettings{
+					Port:      serverPort,
+					Namespace: apiNamespacePrefix,
+					Version:   apiVersionPrefix,
+					Hosts:     apiHost,
+					Debug:     apiDebugMode,
+					Auth:      configFile.API.Auth,
+				},
+				Lint:         lintRules,
+				Policy:       configFile.Policy,
+				Environments: configFile.Environments,
+				Environment:  configFile.Environment,
+			}.Redacted()
+
+			logger.Custom(format, template).WithFormattedOutput(&effective, os.Stdout)
+		},
+	}
+)
+
+// initConfig - Fills in the settings that were not provided as flags.
+// Precedence: flags, then environment variables, then the selected environment, then the configuration file, then defaults.
+func initConfig() {
+	path, found := configPath, configPath != ""
+
+	if !found {
+		path, found = c.Find()
+	}
+
+	if found {
+		file, err := c.Load(path)
+
+		if err != nil {
+			message := logger.ApplicationError{Error: err.Error()}
+			logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+			os.Exit(INVALID_INPUT_ERROR)
+		}
+
+		configFile = file
+	}
+
+	if ev := os.Getenv("DM_ENV"); environment == "" && ev != "" {
+		environment = ev
+	}
+
+	if environment != "" {
+		file, err := configFile.WithEnvironment(environment)
+
+		if err != nil {
+			message := logger.ApplicationError{Error: err.Error()}
+			logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+			os.Exit(INVALID_INPUT_ERROR)
+		}
+
+		configFile = file
+	}
+
+	cmd, _, err := rootCmd.Find(os.Args[1:])
+
+	if err != nil {
+		cmd = rootCmd
+	}
+
+	port := ""
+
+	if configFile.API.Port != 0 {
+		port = strconv.Itoa(configFile.API.Port)
+	}
+
+	debug := ""
+
+	if configFile.API.Debug {
+		debug = "true"
+	}
+
+	settings := []struct {
+		owner *pflag.FlagSet
+		name  string
+		env   string
+		value string
+	}{
+		{rootCmd.PersistentFlags(), "adapter", "", configFile.Adapter},
+		{migrateCmd.PersistentFlags(), "database-url", "DATABASE_URL", configFile.DatabaseURL},
+		{rootCmd.PersistentFlags(), "directory", "MIGRATIONS_DIRECTORY", strings.Join(configFile.Directory, ",")},
+		{rootCmd.PersistentFlags(), "table", "MIGRATIONS_TABLE", configFile.Table},
+		{rootCmd.PersistentFlags(), "output-format", "", configFile.OutputFormat},
+		{rootCmd.PersistentFlags(), "output-template", "", configFile.OutputTemplate},
+		{apiCmd.PersistentFlags(), "port", "", port},
+		{apiCmd.PersistentFlags(), "version", "API_VERSION", configFile.API.Version},
+		{apiCmd.PersistentFlags(), "hosts", "ALLOWED_HOST", configFile.API.Hosts},
+		{apiCmd.PersistentFlags(), "debug", "", debug},
+	}
+
+	for _, setting := range settings {
+		if err := applySetting(cmd, setting.owner, setting.name, setting.env, setting.value); err != nil {
+			message := logger.ApplicationError{Error: err.Error()}
+			logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+			os.Exit(INVALID_INPUT_ERROR)
+		}
+	}
+
+	// NOTE: The API namespace cannot be set with a flag
+	if np := os.Getenv("API_NAMESPACE"); np != "" {
+		apiNamespacePrefix = np
+	} else if configFile.API.Namespace != "" {
+		apiNamespacePrefix = configFile.API.Namespace
+	}
+
+	lintRules = configFile.Lint
+}
+
+// applySetting - Sets a flag that was not provided on the command line, preferring the environment variable to the file.
+// Flags of other commands (owner) are set as well, since `dm config show` reports every setting.
+func applySetting(cmd *cobra.Command, owner *pflag.FlagSet, name, env, value string) error {
+	flags := cmd.Flags()
+
+	if flags.Lookup(name) == nil {
+		flags = owner
+	}
+
+	if flags.Lookup(name) == nil || flags.Changed(name) {
+		return nil
+	}
+
+	if ev := os.Getenv(env); env != "" && ev != "" {
+		value = ev
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	if err := flags.Set(name, value); err != nil {
+		return fmt.Errorf("Invalid value '%v' for %v: %v", value, name, err)
+	}
+
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(showConfigCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApplySetting(t *testing.T) {
+	scenarios := []struct {
+		description string
+		flag        string
+		env         string
+		file        string
+		expected    string
+	}{
+		{"default", "", "", "", "_migrations"},
+		{"file", "", "", "file_migrations", "file_migrations"},
+		{"environment variable", "", "env_migrations", "file_migrations", "env_migrations"},
+		{"flag", "flag_migrations", "env_migrations", "file_migrations", "flag_migrations"},
+	}
+
+	for _, scenario := range scenarios {
+		t.Setenv("DM_TEST_TABLE", scenario.env)
+
+		cmd := &cobra.Command{Use: "migrate"}
+		cmd.Flags().String("table", "_migrations", "")
+
+		if scenario.flag != "" {
+			_ = cmd.Flags().Set("table", scenario.flag)
+		}
+
+		if err := applySetting(cmd, cmd.Flags(), "table", "DM_TEST_TABLE", scenario.file); err != nil {
+			t.Fatalf(`%v: expected no error, but got %v`, scenario.description, err)
+		}
+
+		if value, _ := cmd.Flags().GetString("table"); value != scenario.expected {
+			t.Errorf(`%v: expected %v, but got %v`, scenario.description, scenario.expected, value)
+		}
+	}
+}
+
+func TestApplySettingOfAnotherCommand(t *testing.T) {
+	owner := &cobra.Command{Use: "api"}
+	owner.Flags().Int("port", 3809, "")
+
+	// NOTE: The flag is not defined by the command being run
+	if err := applySetting(&cobra.Command{Use: "version"}, owner.Flags(), "port", "", "4000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if port, _ := owner.Flags().GetInt("port"); port != 4000 {
+		t.Errorf(`expected 4000, but got %v`, port)
+	}
+
+	// NOTE: Flags that no command defines are ignored
+	if err := applySetting(&cobra.Command{Use: "version"}, owner.Flags(), "unknown", "", "value"); err != nil {
+		t.Errorf(`expected no error, but got %v`, err)
+	}
+}
+
+func TestApplySettingInvalidValue(t *testing.T) {
+	cmd := &cobra.Command{Use: "api"}
+	cmd.Flags().Int("port", 3809, "")
+
+	err := applySetting(cmd, cmd.Flags(), "port", "", "http")
+
+	if err == nil || !strings.Contains(err.Error(), "Invalid value 'http' for port") {
+		t.Errorf(`expected the value to be invalid, but got %v`, err)
+	}
+}
@@ -1,6 +1,43 @@
 package cmd
 
+import (
+	"errors"
+
+	"github.com/oleoneto/dm/migrations"
+)
+
 var (
-	INVALID_INPUT_ERROR = 20
-	DATABASE_ERROR      = 30
+	INVALID_INPUT_ERROR     = 20
+	DATABASE_ERROR          = 30
+	CHECKSUM_MISMATCH_ERROR = 40
+	LOCK_ERROR              = 50
+	DIRTY_ERROR             = 60
+	POLICY_ERROR            = 70
 )
+
+// RefusedError - The action was refused by the policy of the database, or was not confirmed.
+type RefusedError struct {
+	Reason string
+}
+
+func (error RefusedError) Error() string {
+	return error.Reason
+}
+
+// exitCode - Maps errors returned by the runner, and refusals, to the exit code of the CLI.
+func exitCode(err error) int {
+	switch {
+	case errors.As(err, new(*migrations.ValidationError)):
+		return INVALID_INPUT_ERROR
+	case errors.As(err, new(*migrations.ChecksumMismatchError)):
+		return CHECKSUM_MISMATCH_ERROR
+	case errors.As(err, new(*migrations.LockError)):
+		return LOCK_ERROR
+	case errors.As(err, new(*migrations.DirtyError)):
+		return DIRTY_ERROR
+	case errors.As(err, new(*RefusedError)):
+		return POLICY_ERROR
+	default:
+		return DATABASE_ERROR
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"strings"
 
@@ -30,7 +31,7 @@ var (
 				os.Exit(1)
 			}
 
-			storeAdapter = selectedAdapter
+			storeAdapter = selectedAdapter(databaseUrl)
 			runner.SetStore(storeAdapter)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
@@ -54,7 +55,11 @@ var (
 				os.Exit(1)
 			}
 
-			files := migrations.LoadFiles(directory, &FilePattern)
+			files := []fs.FileInfo{}
+
+			for _, directory := range directories {
+				files = append(files, migrations.LoadFiles(directory, &FilePattern)...)
+			}
 
 			for _, file := range files {
 				if strings.Contains(strcase.ToSnake(file.Name()), strcase.ToSnake(version.Value)) {
@@ -68,7 +73,7 @@ var (
 				filecontent, _ = readFromStdin()
 			}
 
-			migration := runner.Generate(mformat, filecontent, version.Value, directory)
+			migration := runner.Generate(mformat, filecontent, version.Value, outputDirectory())
 
 			if migration.FileName == "" {
 				message := logger.ApplicationError{Error: "Error: migration file not created."}
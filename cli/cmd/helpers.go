@@ -8,6 +8,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/oleoneto/dm/logger"
 )
@@ -63,6 +64,53 @@ func parsedVersionFlag(flag string) (VersionFlag, error) {
 	return parsedFlag, new(InvalidFlagError)
 }
 
+// validateStepsFlag - Steps select migrations on their own, so they cannot be combined with a NAME|VERSION argument.
+func validateStepsFlag(version VersionFlag) error {
+	if steps < 0 {
+		return invalidFlag("--steps must be a positive number")
+	}
+
+	if steps > 0 && version.Value != "" {
+		return invalidFlag("NAME|VERSION cannot be combined with --steps")
+	}
+
+	return nil
+}
+
+// TimeFlagLayouts - Layouts accepted by flags that take a point in time.
+var TimeFlagLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+func parsedTimeFlag(flag string) (time.Time, error) {
+	if flag == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range TimeFlagLayouts {
+		if parsed, err := time.Parse(layout, flag); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, invalidFlag(fmt.Sprintf("invalid time '%v' (expected RFC3339 or 2006-01-02)", flag))
+}
+
+// invalidFlag - Logs the reason why a flag was rejected.
+func invalidFlag(reason string) error {
+	message := logger.ApplicationMessage{Message: fmt.Sprintf("Error: %v", reason)}
+	logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+
+	return new(InvalidFlagError)
+}
+
+// outputDirectory - Where new migration files are written: the first of the migrations directories.
+func outputDirectory() string {
+	if len(directories) == 0 {
+		return "./migrations"
+	}
+
+	return directories[0]
+}
+
 func readFromStdin() (input string, err error) {
 	if flag.NArg() == 0 {
 		reader := bufio.NewReader(os.Stdin)
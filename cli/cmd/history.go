@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"github.com/oleoneto/dm/logger"
+	"github.com/oleoneto/dm/migrations"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyAction string
+	historySince  string
+	historyUntil  string
+	historyLimit  = migrations.DefaultHistoryEntries
+
+	historyCmd = &cobra.Command{
+		Use:   "history [NAME|VERSION]",
+		Short: "Shows every migration, rollback, and failure recorded in the database",
+		Long: `Shows every migration, rollback, and failure recorded in the database, most recent first.
+
+Unlike the migrations table, the history is never modified by a rollback.
+Entries can be filtered by migration, action, and time range. Times can be given as RFC3339 timestamps or dates (2006-01-02).`,
+		Args: cobra.MaximumNArgs(1),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			validateDatabaseConfig()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			filter, err := parsedHistoryFilter(args)
+
+			if err != nil {
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			history, err := runner.History(filter)
+
+			if err != nil {
+				os.Exit(exitCode(err))
+			}
+
+			logger.Custom(format, template).WithFormattedOutput(&history, os.Stdout)
+		},
+	}
+)
+
+func parsedHistoryFilter(args []string) (migrations.HistoryFilter, error) {
+	filter := migrations.HistoryFilter{Action: historyAction, Limit: historyLimit}
+
+	if len(args) == 1 {
+		version, err := parsedVersionFlag(args[0])
+
+		if err != nil {
+			return filter, err
+		}
+
+		filter.Migration = version.Value
+
+		if version.Type == "Name" {
+			filter.Migration = strcase.ToCamel(version.Value)
+		}
+	}
+
+	if historyAction != "" && !isHistoryAction(historyAction) {
+		return filter, invalidFlag(fmt.Sprintf("action must be one of: %v", strings.Join(migrations.HistoryActions, ", ")))
+	}
+
+	var err error
+
+	if filter.Since, err = parsedTimeFlag(historySince); err != nil {
+		return filter, err
+	}
+
+	if filter.Until, err = parsedTimeFlag(historyUntil); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
+func isHistoryAction(action string) bool {
+	for _, known := range migrations.HistoryActions {
+		if action == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyAction, "action", historyAction, fmt.Sprintf("only show entries for this action (%v)", strings.Join(migrations.HistoryActions, ", ")))
+	historyCmd.Flags().StringVar(&historySince, "since", historySince, "only show entries recorded at or after this time")
+	historyCmd.Flags().StringVar(&historyUntil, "until", historyUntil, "only show entries recorded at or before this time")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", historyLimit, "maximum number of entries")
+
+	historyCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url")
+	historyCmd.MarkFlagRequired("database-url")
+	historyCmd.MarkFlagRequired("adapter")
+	historyCmd.MarkFlagRequired("table")
+}
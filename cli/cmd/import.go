@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/oleoneto/dm/logger"
+	"github.com/oleoneto/dm/migrations"
+	"github.com/spf13/cobra"
+)
+
+type ImportOutput struct {
+	migrations.Import
+	Written  []string              `json:"written"`
+	Recorded migrations.Migrations `json:"recorded"`
+}
+
+func (o ImportOutput) Description() string {
+	description := o.Import.Description()
+	description += fmt.Sprintf("Wrote %v file(s) to %v\n", len(o.Written), outputDirectory())
+
+	if importRecord {
+		description += fmt.Sprintf("Recorded %v applied migration(s) in %v\n", len(o.Recorded), table)
+	}
+
+	return description
+}
+
+var (
+	importDirectory   = ""
+	importSourceTable = ""
+	importRecord      = false
+
+	importCmd = &cobra.Command{
+		Use:       "import SOURCE",
+		Short:     "Import migrations from another tool (golang-migrate, goose, flyway, rails)",
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{string(migrations.GolangMigrate), string(migrations.Goose), string(migrations.Flyway), string(migrations.Rails)},
+		Run: func(cmd *cobra.Command, args []string) {
+			source := migrations.ImportSource(args[0])
+
+			imported, err := migrations.ReadImport(source, importDirectory, adapter)
+
+			if err != nil {
+				message := logger.ApplicationError{Error: err.Error()}
+				logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			written, err := imported.Write(outputDirectory())
+
+			if err != nil {
+				message := logger.ApplicationError{Error: err.Error()}
+				logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			output := ImportOutput{Import: imported, Written: written, Recorded: migrations.Migrations{}}
+
+			// NOTE: The history is only copied when asked for, so a DATABASE_URL set in the environment or the config file is not written to.
+			// The database url flag cannot imply it, since the config marks the flag as changed when it sets it from either of them.
+			if importRecord {
+				validateDatabaseConfig()
+
+				sourceTable := importSourceTable
+
+				if sourceTable == "" {
+					sourceTable = migrations.ImportSchemaTables[source]
+				}
+
+				output.Recorded, err = runner.ImportHistory(imported, sourceTable)
+
+				if err != nil {
+					os.Exit(exitCode(err))
+				}
+			}
+
+			logger.Custom(format, template).WithFormattedOutput(&output, os.Stdout)
+		},
+	}
+)
+
+func init() {
+	importCmd.Flags().StringVar(&importDirectory, "from", importDirectory, "directory containing the migrations of the other tool")
+	importCmd.Flags().StringVar(&importSourceTable, "source-table", importSourceTable, "schema table of the other tool (defaults to the tool's default)")
+	importCmd.Flags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url (used with --record)")
+	importCmd.Flags().BoolVar(&importRecord, "record", importRecord, "copy the history of applied migrations into the migrations table")
+	importCmd.MarkFlagRequired("from")
+}
@@ -1,14 +1,21 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/iancoleman/strcase"
 	"github.com/oleoneto/dm/logger"
+	"github.com/oleoneto/dm/migrations"
 	"github.com/spf13/cobra"
 )
 
 var (
+	acceptDrift = false
+	dryRun      = false
+	steps       = 0
+
 	migrateCmd = &cobra.Command{
 		Use:     "migrate NAME|VERSION",
 		Short:   "Run migration(s)",
@@ -29,7 +36,14 @@ var (
 				}
 			}
 
-			list := runner.PendingMigrations(directory, &FilePattern)
+			if err = validateStepsFlag(version); err != nil {
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			runner.SetDryRun(dryRun)
+			runner.SetAcceptDrift(acceptDrift)
+
+			list := runner.PendingMigrations(directories, &FilePattern)
 
 			if version.Value != "" {
 				sequence, found := list.Find(strcase.ToCamel(version.Value))
@@ -43,13 +57,56 @@ var (
 				list = sequence
 			}
 
-			runner.Up(list)
+			if steps > 0 {
+				list = list.Take(steps)
+			}
+
+			destructive, err := checkDestructive(list)
+
+			if err != nil {
+				refuse(err)
+			}
+
+			if dryRun {
+				plan, err := runner.PlanUp(list)
+
+				if err != nil {
+					exitWithDriftHint(err)
+				}
+
+				logger.Custom(format, template).WithFormattedOutput(&plan, os.Stdout)
+				return
+			}
+
+			if list.Size() > 0 {
+				if err = confirmAction(fmt.Sprintf("Apply %v migration(s)", list.Size()), list.ToSlice(), destructive); err != nil {
+					refuse(err)
+				}
+			}
+
+			if err = runner.Up(list); err != nil {
+				exitWithDriftHint(err)
+			}
 		},
 	}
 )
 
+// exitWithDriftHint - Exits with the code of the error, explaining how drift is accepted if migration files were modified.
+func exitWithDriftHint(err error) {
+	if errors.As(err, new(*migrations.ChecksumMismatchError)) {
+		message := logger.ApplicationError{Error: "Use --accept-drift to record the new checksums of the modified migrations."}
+		logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+	}
+
+	os.Exit(exitCode(err))
+}
+
 func init() {
 	migrateCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url")
+	migrateCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", dryRun, "show the planned changes without modifying the database")
+	migrateCmd.Flags().IntVar(&steps, "steps", steps, "run only the next N pending migrations")
+	migrateCmd.PersistentFlags().BoolVar(&acceptDrift, "accept-drift", acceptDrift, "record new checksums for modified migration files")
+	migrateCmd.Flags().BoolVar(&allowDestructive, "allow-destructive", allowDestructive, "run migrations that drop or truncate tables when the config refuses them")
 	migrateCmd.MarkFlagRequired("database-url")
 	migrateCmd.MarkFlagRequired("adapter")
 	migrateCmd.MarkFlagRequired("table")
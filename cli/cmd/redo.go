@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	redoSteps = 1
+
+	redoCmd = &cobra.Command{
+		Use:   "redo",
+		Short: "Rollback and re-run the most recent migration(s)",
+		Long: `Rollback and re-run the most recent migration(s).
+
+Reverts the last N applied migrations (--steps, default 1) and runs them again from their files.
+Useful when iterating on a migration during development.`,
+		Args: cobra.NoArgs,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			validateDatabaseConfig()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if redoSteps < 1 {
+				invalidFlag("--steps must be a positive number")
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			if err := refuseRollback(); err != nil {
+				refuse(err)
+			}
+
+			applied := runner.AppliedMigrations(directories, &FilePattern, false)
+			applied.Reverse()
+			applied = applied.Take(redoSteps)
+
+			if applied.Size() > 0 {
+				if err := confirmAction(fmt.Sprintf("Redo %v migration(s)", applied.Size()), applied.ToSlice(), true); err != nil {
+					refuse(err)
+				}
+			}
+
+			if err := runner.Redo(redoSteps, directories, &FilePattern); err != nil {
+				os.Exit(exitCode(err))
+			}
+		},
+	}
+)
+
+func init() {
+	redoCmd.Flags().IntVar(&redoSteps, "steps", redoSteps, "number of migrations to redo")
+
+	redoCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url")
+	redoCmd.MarkFlagRequired("database-url")
+	redoCmd.MarkFlagRequired("adapter")
+	redoCmd.MarkFlagRequired("table")
+}
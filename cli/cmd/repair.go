@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iancoleman/strcase"
+	"github.com/oleoneto/dm/migrations"
+	"github.com/spf13/cobra"
+)
+
+var (
+	repairApplied  bool
+	repairReverted bool
+
+	repairCmd = &cobra.Command{
+		Use:   "repair NAME|VERSION",
+		Short: "Clear the dirty state left by a migration that failed part-way",
+		Long: `Clear the dirty state left by a migration that failed part-way.
+
+Migrations that run without a transaction can fail after some of their statements were applied.
+Such migrations are flagged as dirty and dm refuses to migrate or rollback until they are repaired.
+Fix the database by hand, then record the migration as either applied (--applied) or reverted (--reverted).`,
+		Args: cobra.ExactArgs(1),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			validateDatabaseConfig()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			version, err := parsedVersionFlag(args[0])
+
+			if err != nil {
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			if repairApplied == repairReverted {
+				invalidFlag("exactly one of --applied or --reverted is required")
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			target := version.Value
+
+			if version.Type == "Name" {
+				target = strcase.ToCamel(version.Value)
+			}
+
+			list := migrations.LoadMigrations(directories, &FilePattern)
+
+			if err = confirmAction(fmt.Sprintf("Repair '%v'", target), migrations.Migrations{}, false); err != nil {
+				refuse(err)
+			}
+
+			if err = runner.Repair(target, list, repairApplied); err != nil {
+				os.Exit(exitCode(err))
+			}
+		},
+	}
+)
+
+func init() {
+	repairCmd.Flags().BoolVar(&repairApplied, "applied", repairApplied, "record the migration as applied")
+	repairCmd.Flags().BoolVar(&repairReverted, "reverted", repairReverted, "remove the migration from the migrations table")
+
+	repairCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url")
+	repairCmd.MarkFlagRequired("database-url")
+	repairCmd.MarkFlagRequired("adapter")
+	repairCmd.MarkFlagRequired("table")
+}
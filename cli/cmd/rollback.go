@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/iancoleman/strcase"
@@ -9,6 +10,9 @@ import (
 )
 
 var (
+	rollbackBatch     int
+	rollbackLastBatch bool
+
 	rollbackCmd = &cobra.Command{
 		Use:     "rollback NAME|VERSION",
 		Short:   "Rollback migration(s)",
@@ -29,8 +33,23 @@ var (
 				}
 			}
 
+			if version.Value != "" && (rollbackBatch != 0 || rollbackLastBatch) {
+				invalidFlag("NAME|VERSION cannot be combined with --batch or --last-batch")
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			if err = validateStepsFlag(version); err != nil {
+				os.Exit(INVALID_INPUT_ERROR)
+			}
+
+			runner.SetDryRun(dryRun)
+
+			if err = refuseRollback(); err != nil {
+				refuse(err)
+			}
+
 			loadFromDir := true
-			list := runner.AppliedMigrations(directory, &FilePattern, loadFromDir)
+			list := runner.AppliedMigrations(directories, &FilePattern, loadFromDir)
 
 			if list.Size() == 0 {
 				message := logger.ApplicationMessage{Message: "No applied migrations to rollback."}
@@ -38,6 +57,27 @@ var (
 				return
 			}
 
+			if rollbackLastBatch {
+				rollbackBatch = runner.LastBatch()
+
+				if rollbackBatch == 0 {
+					message := logger.ApplicationMessage{Message: "No batches to rollback."}
+					logger.Custom(format, template).WithFormattedOutput(&message, os.Stdout)
+					return
+				}
+			}
+
+			// NOTE: Later batches are rolled back as well. This is done to ensure schema consistency.
+			if rollbackBatch > 0 {
+				list = list.SinceBatch(rollbackBatch)
+
+				if list.Size() == 0 {
+					message := logger.ApplicationMessage{Message: "Nothing to do."}
+					logger.Custom(format, template).WithFormattedOutput(&message, os.Stdout)
+					return
+				}
+			}
+
 			list.Reverse()
 
 			if version.Value != "" {
@@ -52,13 +92,39 @@ var (
 				list = sequence
 			}
 
-			runner.Down(list)
+			if steps > 0 {
+				list = list.Take(steps)
+			}
+
+			if dryRun {
+				plan, err := runner.PlanDown(list)
+
+				if err != nil {
+					os.Exit(exitCode(err))
+				}
+
+				logger.Custom(format, template).WithFormattedOutput(&plan, os.Stdout)
+				return
+			}
+
+			if err = confirmAction(fmt.Sprintf("Rollback %v migration(s)", list.Size()), list.ToSlice(), true); err != nil {
+				refuse(err)
+			}
+
+			if err = runner.Down(list); err != nil {
+				os.Exit(exitCode(err))
+			}
 		},
 	}
 )
 
 func init() {
 	rollbackCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url")
+	rollbackCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", dryRun, "show the planned changes without modifying the database")
+	rollbackCmd.Flags().IntVar(&rollbackBatch, "batch", rollbackBatch, "rollback the migrations applied in this batch (and later batches)")
+	rollbackCmd.Flags().BoolVar(&rollbackLastBatch, "last-batch", rollbackLastBatch, "rollback the migrations applied by the last run of migrate")
+	rollbackCmd.Flags().IntVar(&steps, "steps", steps, "rollback only the last N applied migrations")
+	rollbackCmd.MarkFlagsMutuallyExclusive("batch", "last-batch", "steps")
 	rollbackCmd.MarkFlagRequired("database-url")
 	rollbackCmd.MarkFlagRequired("adapter")
 	rollbackCmd.MarkFlagRequired("table")
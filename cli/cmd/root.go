@@ -11,9 +11,10 @@ import (
 )
 
 var (
-	config       string
+	configPath   string
+	environment  = ""
 	runner       = migrations.Runner{}
-	directory    = "./migrations"
+	directories  = []string{"./migrations"}
 	storeAdapter migrations.Store
 	adapter      = "postgresql"
 	databaseUrl  = os.Getenv("DATABASE_URL")
@@ -21,10 +22,13 @@ var (
 	FilePattern  = migrations.FilePattern
 	format       = "plain"
 	template     = ""
+	lockTimeout  = migrations.DefaultLockTimeout
+	actor        = ""
 
-	SUPPORTED_ADAPTERS = map[string]migrations.Store{
-		"postgresql": stores.Postgres{URL: databaseUrl},
-		// "sqlite3":    stores.SQLite3{URL: databaseUrl},
+	SUPPORTED_ADAPTERS = map[string]func(url string) migrations.Store{
+		"postgresql": func(url string) migrations.Store { return stores.Postgres{URL: url} },
+		"sqlite3":    func(url string) migrations.Store { return stores.SQLite3{URL: url} },
+		"mysql":      func(url string) migrations.Store { return stores.MySQL{URL: url} },
 	}
 
 	rootCmd = &cobra.Command{
@@ -48,28 +52,6 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
-func overrideVariablesFromEnvironment() {
-	if md := os.Getenv("MIGRATIONS_DIRECTORY"); md != "" {
-		directory = md
-	}
-
-	if mt := os.Getenv("MIGRATIONS_TABLE"); mt != "" {
-		table = mt
-	}
-
-	if np := os.Getenv("API_NAMESPACE"); np != "" {
-		apiNamespacePrefix = np
-	}
-
-	if vp := os.Getenv("API_VERSION"); vp != "" {
-		apiVersionPrefix = vp
-	}
-
-	if ah := os.Getenv("ALLOWED_HOST"); ah != "" {
-		apiHost = ah
-	}
-}
-
 func validateDatabaseConfig() {
 	if databaseUrl == "" {
 		message := logger.ApplicationError{
@@ -88,21 +70,29 @@ func validateDatabaseConfig() {
 		os.Exit(102)
 	}
 
-	storeAdapter = selectedAdapter
+	storeAdapter = selectedAdapter(databaseUrl)
 	runner.SetStore(storeAdapter)
 	runner.SetSchemaTable(table)
 	runner.SetLogger(format, template)
+	runner.SetLockTimeout(lockTimeout)
+	runner.SetActor(actor)
+	runner.SetSource(migrations.SourceCLI)
+	runner.SetToolVersion(version.Version)
 }
 
 func init() {
 	// CLI configuration
-	// cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().StringVar(&config, "config", config, "config file")
+	cobra.OnInitialize(initConfig)
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", configPath, "config file (defaults to ./dm.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&environment, "env", "e", environment, "environment defined in the config file")
+	rootCmd.PersistentFlags().BoolVar(&assumeYes, "yes", assumeYes, "do not ask for confirmation")
 
 	// Migrator configuration
 	rootCmd.PersistentFlags().StringVarP(&adapter, "adapter", "a", adapter, "database adapter")
-	rootCmd.PersistentFlags().StringVarP(&directory, "directory", "d", directory, "migrations directory")
+	rootCmd.PersistentFlags().StringSliceVarP(&directories, "directory", "d", directories, "migrations directories (new migrations are written to the first one)")
 	rootCmd.PersistentFlags().StringVarP(&table, "table", "t", table, "table wherein migrations are tracked")
+	rootCmd.PersistentFlags().DurationVar(&lockTimeout, "lock-timeout", lockTimeout, "how long to wait for other migrations to finish")
+	rootCmd.PersistentFlags().StringVar(&actor, "actor", actor, "who is running migrations (defaults to the current user)")
 	rootCmd.PersistentFlags().StringVarP(&format, "output-format", "o", format, "output format")
 	rootCmd.PersistentFlags().StringVarP(&template, "output-template", "y", template, "template (used when output format is 'gotemplate')")
 
@@ -112,11 +102,18 @@ func init() {
 	rootCmd.AddCommand(rollbackCmd)
 	rootCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(unlockCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(baselineCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(repairCmd)
+	rootCmd.AddCommand(redoCmd)
 	rootCmd.AddCommand(cliVersionCmd)
 	rootCmd.AddCommand(apiCmd)
+	rootCmd.AddCommand(configCmd)
 
 	// Runner configuration
 	// These changes can be overridden by validateDatabaseConfig()
-	runner.SetStore(SUPPORTED_ADAPTERS[adapter])
+	runner.SetStore(SUPPORTED_ADAPTERS[adapter](databaseUrl))
 	runner.SetSchemaTable(table)
 }
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	c "github.com/oleoneto/dm/config"
+	"github.com/oleoneto/dm/logger"
+	"github.com/oleoneto/dm/migrations"
+)
+
+var (
+	assumeYes        = false
+	allowDestructive = false
+
+	// confirmationInput - Where confirmations are read from.
+	confirmationInput io.Reader = os.Stdin
+)
+
+// confirmAction - Shows a summary of the changes and asks the user to type a confirmation.
+// Destructive actions always ask; other actions ask when the policy of the database requires it.
+// Non-interactive runs must confirm with `--yes` instead.
+func confirmAction(action string, changes migrations.Migrations, destructive bool) error {
+	if !(destructive || configFile.Confirm) || assumeYes || dryRun {
+		return nil
+	}
+
+	if !isInteractive() {
+		return &RefusedError{Reason: fmt.Sprintf("%v on %v requires confirmation. Use --yes to confirm non-interactively.", action, targetDescription())}
+	}
+
+	expected := confirmationWord()
+
+	fmt.Fprintf(os.Stderr, "%v on %v.\n", action, targetDescription())
+
+	if changes.Len() != 0 {
+		fmt.Fprint(os.Stderr, changes.Description())
+	}
+
+	fmt.Fprintf(os.Stderr, "Type '%v' to continue: ", expected)
+
+	answer, _ := bufio.NewReader(confirmationInput).ReadString('\n')
+
+	if strings.TrimSpace(answer) != expected {
+		return &RefusedError{Reason: "Aborted."}
+	}
+
+	return nil
+}
+
+// refuseRollback - Returns an error if the policy of the database does not permit rollbacks.
+func refuseRollback() error {
+	if dryRun {
+		return nil
+	}
+
+	if !configFile.RollbackAllowed() {
+		return &RefusedError{Reason: fmt.Sprintf("Rollbacks are not allowed on %v.", targetDescription())}
+	}
+
+	pattern, protected, err := configFile.ProtectedBy(databaseUrl)
+
+	if err != nil {
+		return fmt.Errorf("%w: invalid policy: %v", new(migrations.ValidationError), err)
+	}
+
+	if protected {
+		return &RefusedError{Reason: fmt.Sprintf("Rollbacks are not allowed on %v (protected by '%v').", targetDescription(), pattern)}
+	}
+
+	return nil
+}
+
+// checkDestructive - Returns whether the migrations drop or truncate tables.
+// Returns an error if they do and the policy refuses such migrations, unless `--allow-destructive` is set.
+func checkDestructive(list migrations.MigrationList) (bool, error) {
+	destructive := []string{}
+
+	for curr := list.GetHead(); curr != nil; curr = curr.Next() {
+		if statements := curr.Destructive(); len(statements) != 0 {
+			destructive = append(destructive, fmt.Sprintf("%v: %v", curr.Description(), strings.Join(statements, ", ")))
+		}
+	}
+
+	if len(destructive) != 0 && configFile.RefuseDestructive && !allowDestructive {
+		return true, &RefusedError{Reason: fmt.Sprintf(
+			"These migrations destroy data:\n%v\nUse --allow-destructive to run them on %v.",
+			strings.Join(destructive, "\n"),
+			targetDescription(),
+		)}
+	}
+
+	return len(destructive) != 0, nil
+}
+
+// targetDescription - Describes the database that is about to change.
+func targetDescription() string {
+	if environment == "" {
+		return c.RedactURL(databaseUrl)
+	}
+
+	return fmt.Sprintf("environment '%v' (%v)", environment, c.RedactURL(databaseUrl))
+}
+
+// confirmationWord - What the user types to confirm: the name of the environment, when one is selected.
+func confirmationWord() string {
+	if environment == "" {
+		return "yes"
+	}
+
+	return environment
+}
+
+// isInteractive - Whether the user can be asked for confirmation.
+var isInteractive = func() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// refuse - Reports an action that was refused, and exits.
+func refuse(err error) {
+	message := logger.ApplicationError{Error: err.Error()}
+	logger.Custom(format, template).WithFormattedOutput(&message, os.Stderr)
+	os.Exit(exitCode(err))
+}
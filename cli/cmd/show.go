@@ -22,8 +22,7 @@ var (
 		Use:   "all",
 		Short: "List all migrations for a given application",
 		Run: func(cmd *cobra.Command, args []string) {
-			files := migrations.LoadFiles(directory, &FilePattern)
-			list := migrations.BuildMigrations(files, directory, &FilePattern)
+			list := migrations.LoadMigrations(directories, &FilePattern)
 			m := list.ToSlice()
 
 			logger.Custom(format, template).WithFormattedOutput(&m, os.Stdout)
@@ -34,8 +33,9 @@ var (
 		Use:   "applied",
 		Short: "List only applied migrations",
 		Run: func(cmd *cobra.Command, args []string) {
-			loadFromDir := false
-			list := runner.AppliedMigrations(directory, &FilePattern, loadFromDir)
+			// NOTE: Files are loaded to detect applied migrations that were modified since
+			loadFromDir := true
+			list := runner.AppliedMigrations(directories, &FilePattern, loadFromDir)
 			m := list.ToSlice()
 
 			logger.Custom(format, template).WithFormattedOutput(&m, os.Stdout)
@@ -47,7 +47,7 @@ var (
 		Short:   "List only pending migrations",
 		Aliases: []string{"p"},
 		Run: func(cmd *cobra.Command, args []string) {
-			list := runner.PendingMigrations(directory, &FilePattern)
+			list := runner.PendingMigrations(directories, &FilePattern)
 			m := list.ToSlice()
 
 			logger.Custom(format, template).WithFormattedOutput(&m, os.Stdout)
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	unlockCmd = &cobra.Command{
+		Use:   "unlock",
+		Short: "Release a migration lock left behind by an interrupted run",
+		Args:  cobra.NoArgs,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			validateDatabaseConfig()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := runner.ReleaseLock()
+
+			if err != nil {
+				runner.LogError(err.Error())
+				os.Exit(DATABASE_ERROR)
+			}
+		},
+	}
+)
+
+func init() {
+	unlockCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url")
+	unlockCmd.MarkFlagRequired("database-url")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/oleoneto/dm/logger"
@@ -22,24 +23,47 @@ var (
 		Use:   "validate",
 		Short: "Validate the configuration of migration files",
 		Run: func(cmd *cobra.Command, args []string) {
-			files := migrations.LoadFiles(directory, &FilePattern)
+			list := migrations.LoadMigrations(directories, &FilePattern)
 
-			if len(files) == 0 {
+			if list.Size() == 0 {
 				validationOutput := &ValidationOutput{Message: "No migrations found.", Valid: false}
 				logger.Custom(format, template).WithFormattedOutput(validationOutput, os.Stdout)
 				return
 			}
 
-			list := migrations.BuildMigrations(files, directory, &FilePattern)
 			valid, reason := migrations.Validate(list)
 
-			if valid && reason == "" {
-				validationOutput := &ValidationOutput{Message: "Migrations are valid.", Valid: valid}
+			// NOTE: Lint rules are read from the configuration file
+			if valid {
+				valid, reason = migrations.Lint(list, lintRules)
+			}
+
+			if !valid {
+				validationOutput := &ValidationOutput{Message: reason, Valid: valid}
 				logger.Custom(format, template).WithFormattedOutput(validationOutput, os.Stdout)
+				return
+			}
+
+			// NOTE: Applied migrations can only be checked against a database
+			if databaseUrl != "" {
+				validateDatabaseConfig()
+
+				if drifted := runner.DriftedMigrations(directories, &FilePattern); drifted.Len() != 0 {
+					validationOutput := &ValidationOutput{
+						Message: fmt.Sprintf("Applied migrations were modified:\n%v", drifted.Description()),
+						Valid:   false,
+					}
+					logger.Custom(format, template).WithFormattedOutput(validationOutput, os.Stdout)
+					return
+				}
 			}
 
-			validationOutput := &ValidationOutput{Message: reason, Valid: valid}
+			validationOutput := &ValidationOutput{Message: "Migrations are valid.", Valid: valid}
 			logger.Custom(format, template).WithFormattedOutput(validationOutput, os.Stdout)
 		},
 	}
 )
+
+func init() {
+	validateCmd.PersistentFlags().StringVarP(&databaseUrl, "database-url", "u", databaseUrl, "database url (checks applied migrations for changes)")
+}
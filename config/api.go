@@ -1,6 +1,15 @@
 package config
 
+import (
+	"time"
+
+	"github.com/oleoneto/dm/migrations"
+)
+
 type APIConfig struct {
+	/// The database adapter (i.e. postgresql, sqlite3)
+	Adapter string
+
 	/// CORS allowed origin
 	AllowedHost string
 
@@ -10,8 +19,11 @@ type APIConfig struct {
 	/// Enables server debug logs
 	DebugMode bool
 
-	/// The directory containing migration files
-	Directory string
+	/// The directories containing migration files
+	Directories []string
+
+	/// The environment served by the API (i.e. staging), as defined in the configuration file
+	Environment string
 
 	/// The API resource namespace prefix (i.e. migrations)
 	Namespace string
@@ -21,16 +33,34 @@ type APIConfig struct {
 
 	/// The API version prefix (i.e. v1)
 	Version string
+
+	/// The database the server applies migrations to
+	Store migrations.Store
+
+	/// The safeguards of the database (i.e. allow_rollback). Requests are never asked for confirmation
+	Policy Policy
+
+	/// How long requests wait for other migrations to finish
+	LockTimeout time.Duration
+
+	/// Who applies migrations (defaults to the user running the server)
+	Actor string
+
+	/// The version of dm recorded along with applied migrations
+	ToolVersion string
+
+	/// Credentials accepted by the API
+	Auth AuthSettings
 }
 
 func (t APIConfig) IsValid() ([]string, bool) {
 	missing := []string{}
 
-	if t.ConnectionString == "" {
+	if t.ConnectionString == "" || t.Store == nil {
 		missing = append(missing, "DATABASE_URL")
 	}
 
-	if t.Directory == "" {
+	if len(t.Directories) == 0 {
 		missing = append(missing, "MIGRATIONS_DIRECTORY")
 	}
 
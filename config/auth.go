@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	/// Lists migrations and their history
+	RoleRead = "read"
+
+	/// Applies migrations (includes read)
+	RoleMigrate = "migrate"
+
+	/// Rolls back migrations (includes migrate)
+	RoleRollback = "rollback"
+)
+
+var (
+	/// Roles in increasing order of permissions. Each role includes the permissions of the roles before it
+	Roles = []string{RoleRead, RoleMigrate, RoleRollback}
+)
+
+type AuthSettings struct {
+	/// Static tokens sent as `Authorization: Bearer <token>`
+	Tokens []Credential `yaml:"tokens,omitempty" json:"tokens,omitempty"`
+
+	/// Shared secrets used to sign requests with HMAC-SHA256
+	HMAC []Credential `yaml:"hmac,omitempty" json:"hmac,omitempty"`
+
+	/// JSON Web Tokens signed by the keys of a local JWKS file
+	JWT *JWTSettings `yaml:"jwt,omitempty" json:"jwt,omitempty"`
+}
+
+type Credential struct {
+	/// Identifies the caller. Recorded along with the migrations it applies
+	Name string `yaml:"name" json:"name"`
+
+	/// The token or shared secret. References to environment variables (i.e. ${DM_CI_TOKEN}) are expanded
+	Secret string `yaml:"secret" json:"secret"`
+
+	/// One of read, migrate, or rollback
+	Role string `yaml:"role" json:"role"`
+}
+
+type JWTSettings struct {
+	/// The JWKS file containing the public keys that sign tokens
+	JWKS string `yaml:"jwks" json:"jwks"`
+
+	/// The expected issuer (iss), if any
+	Issuer string `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+
+	/// The expected audience (aud), if any
+	Audience string `yaml:"audience,omitempty" json:"audience,omitempty"`
+
+	/// The claim containing the role of the caller (defaults to role)
+	RoleClaim string `yaml:"role_claim,omitempty" json:"role_claim,omitempty"`
+
+	/// The claim identifying the caller (defaults to sub)
+	IdentityClaim string `yaml:"identity_claim,omitempty" json:"identity_claim,omitempty"`
+}
+
+// Enabled - Whether requests must be authenticated.
+func (a AuthSettings) Enabled() bool {
+	return len(a.Tokens) != 0 || len(a.HMAC) != 0 || a.JWT != nil
+}
+
+// Validate - Checks that every credential has a name, a secret, and a known role.
+func (a AuthSettings) Validate() error {
+	credentials := append(append([]Credential{}, a.Tokens...), a.HMAC...)
+
+	for _, credential := range credentials {
+		if credential.Name == "" {
+			return fmt.Errorf("api auth: credentials must have a name")
+		}
+
+		if credential.Secret == "" {
+			return fmt.Errorf("api auth: credential '%v' has no secret", credential.Name)
+		}
+
+		if !IsRole(credential.Role) {
+			return fmt.Errorf("api auth: credential '%v' has an unknown role '%v' (expected one of: %v)", credential.Name, credential.Role, strings.Join(Roles, ", "))
+		}
+	}
+
+	if a.JWT != nil && a.JWT.JWKS == "" {
+		return fmt.Errorf("api auth: jwt requires a jwks file")
+	}
+
+	return nil
+}
+
+// Redacted - Returns a copy of the settings that is safe to print. References to environment variables are kept.
+func (a AuthSettings) Redacted() AuthSettings {
+	redact := func(credentials []Credential) []Credential {
+		redacted := []Credential{}
+
+		for _, credential := range credentials {
+			if !strings.HasPrefix(credential.Secret, "$") {
+				credential.Secret = "xxxxx"
+			}
+
+			redacted = append(redacted, credential)
+		}
+
+		return redacted
+	}
+
+	if len(a.Tokens) != 0 {
+		a.Tokens = redact(a.Tokens)
+	}
+
+	if len(a.HMAC) != 0 {
+		a.HMAC = redact(a.HMAC)
+	}
+
+	return a
+}
+
+// ExpandedSecret - The secret of the credential, with references to environment variables expanded.
+func (c Credential) ExpandedSecret() string {
+	return os.ExpandEnv(c.Secret)
+}
+
+// IsRole - Whether the role is one of `Roles`.
+func IsRole(role string) bool {
+	return rank(role) >= 0
+}
+
+// RoleIncludes - Whether a role has the permissions of the required role.
+func RoleIncludes(role, required string) bool {
+	return IsRole(role) && rank(role) >= rank(required)
+}
+
+func rank(role string) int {
+	for index, known := range Roles {
+		if role == known {
+			return index
+		}
+	}
+
+	return -1
+}
@@ -4,4 +4,9 @@ var (
 	SERVER_ERROR = 500
 	SUCCESS      = 200
 	ACCEPTED     = 202
+	BAD_REQUEST  = 400
+	UNAUTHORIZED = 401
+	FORBIDDEN    = 403
+	NOT_FOUND    = 404
+	CONFLICT     = 409
 )
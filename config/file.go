@@ -0,0 +1,302 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/oleoneto/dm/migrations"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	/// The configuration file discovered in the working directory
+	DefaultFileName = "dm.yaml"
+
+	/// Passwords in connection strings that are not URLs (i.e. user:password@tcp(host:3306)/database)
+	dsnPassword = regexp.MustCompile(`:[^:@/]*@`)
+)
+
+type File struct {
+	/// Where the configuration was loaded from
+	Path string `yaml:"-" json:"path,omitempty"`
+
+	/// The database adapter (i.e. postgresql, sqlite3)
+	Adapter string `yaml:"adapter,omitempty" json:"adapter,omitempty"`
+
+	/// A connection string used to connect to the database
+	DatabaseURL string `yaml:"database_url,omitempty" json:"database_url,omitempty"`
+
+	/// The directories containing migration files. Accepts a single directory or a list
+	Directory Paths `yaml:"directory,omitempty" json:"directory,omitempty"`
+
+	/// The database table containing migration status information
+	Table string `yaml:"table,omitempty" json:"table,omitempty"`
+
+	/// The output format (i.e. plain, json, yaml, gotemplate)
+	OutputFormat string `yaml:"output_format,omitempty" json:"output_format,omitempty"`
+
+	/// The template used when the output format is gotemplate
+	OutputTemplate string `yaml:"output_template,omitempty" json:"output_template,omitempty"`
+
+	/// Settings of the RESTful API
+	API APISettings `yaml:"api,omitempty" json:"api,omitempty"`
+
+	/// Conventions checked by `dm validate`
+	Lint migrations.LintRules `yaml:"lint,omitempty" json:"lint,omitempty"`
+
+	/// Safeguards for the database
+	Policy `yaml:",inline"`
+
+	/// Named databases (i.e. local, staging, production), selected with --env
+	Environments map[string]Environment `yaml:"environments,omitempty" json:"environments,omitempty"`
+
+	/// The selected environment
+	Environment string `yaml:"-" json:"environment,omitempty"`
+}
+
+type Environment struct {
+	Adapter     string `yaml:"adapter,omitempty" json:"adapter,omitempty"`
+	DatabaseURL string `yaml:"database_url,omitempty" json:"database_url,omitempty"`
+	Directory   Paths  `yaml:"directory,omitempty" json:"directory,omitempty"`
+	Table       string `yaml:"table,omitempty" json:"table,omitempty"`
+
+	Policy `yaml:",inline"`
+}
+
+type Policy struct {
+	/// Asks for confirmation before changing the database
+	Confirm bool `yaml:"confirm,omitempty" json:"confirm,omitempty"`
+
+	/// Whether migrations can be rolled back (defaults to true)
+	AllowRollback *bool `yaml:"allow_rollback,omitempty" json:"allow_rollback,omitempty"`
+
+	/// Rollbacks are refused on databases whose URL matches any of these regular expressions
+	Protected []string `yaml:"protected,omitempty" json:"protected,omitempty"`
+
+	/// Refuses migrations that drop or truncate tables, unless they are run with --allow-destructive
+	RefuseDestructive bool `yaml:"refuse_destructive,omitempty" json:"refuse_destructive,omitempty"`
+
+	/// The protected patterns, compiled by `Load()`
+	patterns []*regexp.Regexp
+}
+
+type APISettings struct {
+	Port      int    `yaml:"port,omitempty" json:"port,omitempty"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Version   string `yaml:"version,omitempty" json:"version,omitempty"`
+	Hosts     string `yaml:"hosts,omitempty" json:"hosts,omitempty"`
+	Debug     bool   `yaml:"debug,omitempty" json:"debug,omitempty"`
+
+	/// Credentials accepted by the API. Requests are not authenticated when none are set
+	Auth AuthSettings `yaml:"auth,omitempty" json:"auth,omitempty"`
+}
+
+type Paths []string
+
+// UnmarshalYAML - Accepts either a single path or a list of paths.
+func (p *Paths) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+
+	if err := unmarshal(&path); err == nil {
+		*p = Paths{path}
+		return nil
+	}
+
+	var paths []string
+
+	if err := unmarshal(&paths); err != nil {
+		return err
+	}
+
+	*p = paths
+
+	return nil
+}
+
+// Find - Looks for the configuration file in the working directory.
+func Find() (string, bool) {
+	if _, err := os.Stat(DefaultFileName); err != nil {
+		return "", false
+	}
+
+	return DefaultFileName, true
+}
+
+// Load - Reads a configuration file. Unknown settings are rejected, so typos do not go unnoticed.
+func Load(path string) (File, error) {
+	file := File{Path: path}
+
+	contents, err := os.ReadFile(path)
+
+	if err != nil {
+		return file, err
+	}
+
+	if err = yaml.UnmarshalStrict(contents, &file); err != nil {
+		return file, fmt.Errorf("invalid configuration file %v: %w", path, err)
+	}
+
+	if err = file.API.Auth.Validate(); err != nil {
+		return file, fmt.Errorf("invalid configuration file %v: %w", path, err)
+	}
+
+	if err = file.Policy.compile(); err != nil {
+		return file, fmt.Errorf("invalid configuration file %v: %w", path, err)
+	}
+
+	for name, environment := range file.Environments {
+		if err = environment.Policy.compile(); err != nil {
+			return file, fmt.Errorf("invalid configuration file %v: environment '%v': %w", path, name, err)
+		}
+
+		file.Environments[name] = environment
+	}
+
+	return file, nil
+}
+
+// WithEnvironment - Returns the configuration with the settings of a named environment applied.
+func (f File) WithEnvironment(name string) (File, error) {
+	environment, found := f.Environments[name]
+
+	if !found {
+		return f, fmt.Errorf("unknown environment '%v' (available: %v)", name, strings.Join(f.EnvironmentNames(), ", "))
+	}
+
+	f.Environment = name
+
+	if environment.Adapter != "" {
+		f.Adapter = environment.Adapter
+	}
+
+	if environment.DatabaseURL != "" {
+		f.DatabaseURL = environment.DatabaseURL
+	}
+
+	if len(environment.Directory) != 0 {
+		f.Directory = environment.Directory
+	}
+
+	if environment.Table != "" {
+		f.Table = environment.Table
+	}
+
+	if environment.Confirm {
+		f.Confirm = true
+	}
+
+	if environment.AllowRollback != nil {
+		f.AllowRollback = environment.AllowRollback
+	}
+
+	if environment.RefuseDestructive {
+		f.RefuseDestructive = true
+	}
+
+	f.Protected = append(append([]string{}, f.Protected...), environment.Protected...)
+	f.patterns = append(append([]*regexp.Regexp{}, f.patterns...), environment.patterns...)
+
+	return f, nil
+}
+
+// EnvironmentNames - The names of the environments, in alphabetical order.
+func (f File) EnvironmentNames() []string {
+	names := []string{}
+
+	for name := range f.Environments {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Redacted - Returns a copy of the configuration that is safe to print.
+func (f File) Redacted() File {
+	f.DatabaseURL = RedactURL(f.DatabaseURL)
+	f.API.Auth = f.API.Auth.Redacted()
+
+	environments := map[string]Environment{}
+
+	for name, environment := range f.Environments {
+		environment.DatabaseURL = RedactURL(environment.DatabaseURL)
+		environments[name] = environment
+	}
+
+	if len(environments) != 0 {
+		f.Environments = environments
+	}
+
+	return f
+}
+
+// RollbackAllowed - Whether the policy permits rollbacks.
+func (p Policy) RollbackAllowed() bool {
+	return p.AllowRollback == nil || *p.AllowRollback
+}
+
+// ProtectedBy - Returns the pattern that protects a database URL from rollbacks, if any.
+// Returns an error if a protected pattern is invalid.
+func (p Policy) ProtectedBy(connection string) (string, bool, error) {
+	// NOTE: Policies that were not loaded from a file (i.e. created in code) are compiled when checked
+	if len(p.patterns) != len(p.Protected) {
+		if err := p.compile(); err != nil {
+			return "", false, err
+		}
+	}
+
+	for _, pattern := range p.patterns {
+		if pattern.MatchString(connection) {
+			return pattern.String(), true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// compile - Compiles the protected patterns, so they are not compiled on every check.
+func (p *Policy) compile() error {
+	p.patterns = []*regexp.Regexp{}
+
+	for _, pattern := range p.Protected {
+		compiled, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return fmt.Errorf("protected pattern '%v': %w", pattern, err)
+		}
+
+		p.patterns = append(p.patterns, compiled)
+	}
+
+	return nil
+}
+
+// RedactURL - Hides the password of a connection string.
+func RedactURL(connection string) string {
+	if parsed, err := url.Parse(connection); err == nil && parsed.User != nil {
+		return parsed.Redacted()
+	}
+
+	return dsnPassword.ReplaceAllString(connection, ":xxxxx@")
+}
+
+// MARK: - Formattable
+
+func (f File) Description() string {
+	contents, _ := yaml.Marshal(f)
+
+	if f.Path == "" {
+		return fmt.Sprintf("# No configuration file\n%v", string(contents))
+	}
+
+	if f.Environment != "" {
+		return fmt.Sprintf("# %v (environment: %v)\n%v", f.Path, f.Environment, string(contents))
+	}
+
+	return fmt.Sprintf("# %v\n%v", f.Path, string(contents))
+}
@@ -0,0 +1,19 @@
+-- +dm Engine postgresql
+
+-- +dm Up
+ALTER TABLE articles ADD COLUMN slug VARCHAR;
+
+CREATE FUNCTION articles_set_slug() RETURNS trigger AS $$
+BEGIN
+  NEW.slug := lower(replace(NEW.title, ' ', '-'));
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER articles_slug BEFORE INSERT OR UPDATE ON articles
+  FOR EACH ROW EXECUTE FUNCTION articles_set_slug();
+
+-- +dm Down
+DROP TRIGGER articles_slug ON articles;
+DROP FUNCTION articles_set_slug();
+ALTER TABLE articles DROP COLUMN slug;
@@ -48,6 +48,9 @@ func (l Logger) WithFormattedOutput(data Formattable, ioWriter io.Writer) {
 }
 
 func (l *Logger) ReleaseCachedMessages(ioWriter io.Writer) {
+	// NOTE: Released messages are not written again by subsequent releases
+	defer func() { l.cachedMessages = nil }()
+
 	switch l.config.Format {
 	case "plain":
 		messages := []string{}
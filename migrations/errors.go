@@ -1,9 +1,42 @@
 package migrations
 
+import (
+	"fmt"
+	"strings"
+)
+
 type EngineError struct{}
 
 type ValidationError struct{}
 
+// ChecksumMismatchError - Applied migrations were modified after being applied.
+type ChecksumMismatchError struct {
+	Versions []string
+}
+
+// LockError - The migration lock could not be acquired in time. The holder is known for lock tables only.
+type LockError struct {
+	Holder *LockHolder
+}
+
+type DirtyError struct{}
+
+// StatementError - Identifies the statement of a migration that failed.
+type StatementError struct {
+	Index int
+	Err   error
+}
+
+type InvalidSQLMigrationError struct {
+	Line   int
+	Reason string
+}
+
+type ImportError struct {
+	File   string
+	Reason string
+}
+
 func (error EngineError) Error() string {
 	return "engine returned an error"
 }
@@ -11,3 +44,43 @@ func (error EngineError) Error() string {
 func (error ValidationError) Error() string {
 	return "validation error"
 }
+
+func (error ChecksumMismatchError) Error() string {
+	if len(error.Versions) == 0 {
+		return "checksum mismatch"
+	}
+
+	return fmt.Sprintf("checksum mismatch: applied migrations were modified (%v)", strings.Join(error.Versions, ", "))
+}
+
+func (error LockError) Error() string {
+	if error.Holder == nil {
+		return "unable to acquire migration lock"
+	}
+
+	return fmt.Sprintf("unable to acquire migration lock held by %v", error.Holder.Description())
+}
+
+func (error DirtyError) Error() string {
+	return "dirty migrations must be repaired"
+}
+
+func (error StatementError) Error() string {
+	return fmt.Sprintf("statement %v: %v", error.Index, error.Err)
+}
+
+func (error StatementError) Unwrap() error {
+	return error.Err
+}
+
+func (error InvalidSQLMigrationError) Error() string {
+	return fmt.Sprintf("invalid sql migration (line %v): %v", error.Line, error.Reason)
+}
+
+func (error ImportError) Error() string {
+	if error.File == "" {
+		return fmt.Sprintf("import failed: %v", error.Reason)
+	}
+
+	return fmt.Sprintf("import failed (%v): %v", error.File, error.Reason)
+}
@@ -0,0 +1,84 @@
+package migrations
+
+import "time"
+
+const (
+	EventRunStarted  = "run_started"
+	EventStarted     = "started"
+	EventStatement   = "statement"
+	EventSucceeded   = "succeeded"
+	EventFailed      = "failed"
+	EventRunFinished = "run_finished"
+)
+
+// Event - Reports the progress of `Up()` and `Down()`. See `Runner.SetEventHandler()`.
+//
+// A run reports `EventRunStarted`, then `EventStarted`, one `EventStatement` per statement,
+// and `EventSucceeded` or `EventFailed` for each migration, and finally `EventRunFinished`.
+type Event struct {
+	Type string `json:"type"`
+
+	// Direction - Either up (applied) or down (rolled back).
+	Direction string `json:"direction,omitempty"`
+
+	// Version, Name - The migration the event is about. Not set for run events.
+	Version string `json:"version,omitempty"`
+	Name    string `json:"name,omitempty"`
+
+	// Statement - The index of the statement that was executed. Only set for statement events.
+	Statement *int `json:"statement,omitempty"`
+
+	// Total, Completed - The number of migrations in the run, and how many of them succeeded. Only set for run events.
+	Total     int `json:"total,omitempty"`
+	Completed int `json:"completed,omitempty"`
+
+	// LockWaitMs - How long the run waited for the migration lock. Only set for `EventRunStarted`.
+	LockWaitMs int64 `json:"lock_wait_ms,omitempty"`
+
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// SetEventHandler - Called as runs, migrations, and statements start and finish. Handlers must not block.
+func (runner *Runner) SetEventHandler(handler func(Event)) {
+	runner.events = handler
+}
+
+// emit - Reports an event to the event handler, if any. Events other than starts report the time elapsed since `started`.
+func (runner *Runner) emit(event Event, started time.Time, err error) {
+	if runner.events == nil {
+		return
+	}
+
+	event.Time = time.Now()
+
+	if event.Type != EventStarted && event.Type != EventRunStarted {
+		event.DurationMs = event.Time.Sub(started).Milliseconds()
+	}
+
+	if event.Type == EventRunStarted {
+		event.LockWaitMs = runner.lockWait.Milliseconds()
+	}
+
+	if err != nil {
+		event.Error = err.Error()
+	}
+
+	runner.events(event)
+}
+
+func migrationEvent(kind, direction string, migration Migration) Event {
+	return Event{Type: kind, Direction: direction, Version: migration.Version, Name: migration.Name}
+}
+
+func statementEvent(direction string, migration Migration, index int) Event {
+	event := migrationEvent(EventStatement, direction, migration)
+	event.Statement = &index
+
+	return event
+}
+
+func runEvent(kind, direction string, total, completed int) Event {
+	return Event{Type: kind, Direction: direction, Total: total, Completed: completed}
+}
@@ -0,0 +1,71 @@
+package migrations
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+)
+
+const (
+	SourceCLI = "cli"
+	SourceAPI = "api"
+)
+
+// Execution - Describes how, where, and by whom an applied migration was run.
+type Execution struct {
+	DurationMs  int64  `yaml:"-" json:"duration_ms,omitempty" db:"duration_ms"`
+	AppliedBy   string `yaml:"-" json:"applied_by,omitempty" db:"applied_by"`
+	Host        string `yaml:"-" json:"host,omitempty" db:"host"`
+	ToolVersion string `yaml:"-" json:"dm_version,omitempty" db:"dm_version"`
+	Source      string `yaml:"-" json:"source,omitempty" db:"source"`
+}
+
+// Summary - A short description of the execution. Empty for migrations applied before executions were recorded.
+func (E Execution) Summary() string {
+	if E.AppliedBy == "" {
+		return ""
+	}
+
+	summary := fmt.Sprintf("applied by %v", E.AppliedBy)
+
+	if E.Host != "" {
+		summary += fmt.Sprintf("@%v", E.Host)
+	}
+
+	if E.Source != "" {
+		summary += fmt.Sprintf(" via %v", E.Source)
+	}
+
+	if E.ToolVersion != "" {
+		summary += fmt.Sprintf(" (dm %v)", E.ToolVersion)
+	}
+
+	return summary + fmt.Sprintf(" in %vms", E.DurationMs)
+}
+
+// CurrentActor - The name of the operating system user running the process.
+func CurrentActor() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+// execution - Describes the environment in which the runner applies migrations.
+func (runner *Runner) execution() Execution {
+	actor := runner.actor
+
+	if actor == "" {
+		actor = CurrentActor()
+	}
+
+	host, _ := os.Hostname()
+
+	return Execution{
+		AppliedBy:   actor,
+		Host:        host,
+		ToolVersion: runner.toolVersion,
+		Source:      runner.source,
+	}
+}
@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"os"
+	"testing"
+)
+
+func TestExecutionSummary(t *testing.T) {
+	// Scenario 1: Migrations applied before executions were recorded
+	execution := Execution{}
+
+	if execution.Summary() != "" {
+		t.Errorf(`expected an empty summary, but got %v`, execution.Summary())
+	}
+
+	// Scenario 2: A recorded execution
+	execution = Execution{DurationMs: 42, AppliedBy: "deploy", Host: "web-1", ToolVersion: "3.1.0", Source: SourceAPI}
+
+	if execution.Summary() != "applied by deploy@web-1 via api (dm 3.1.0) in 42ms" {
+		t.Errorf(`expected a different summary, but got %v`, execution.Summary())
+	}
+}
+
+func TestRunnerExecution(t *testing.T) {
+	runner := testRunner()
+	host, _ := os.Hostname()
+
+	// Scenario 1: Defaults to the current user
+	execution := runner.execution()
+
+	if execution.AppliedBy != CurrentActor() || execution.Host != host {
+		t.Errorf(`expected execution by %v@%v, but got %v@%v`, CurrentActor(), host, execution.AppliedBy, execution.Host)
+	}
+
+	// Scenario 2: Explicit actor, source and version
+	runner.SetActor("deploy")
+	runner.SetSource(SourceAPI)
+	runner.SetToolVersion("3.1.0")
+
+	execution = runner.execution()
+
+	if execution.AppliedBy != "deploy" || execution.Source != SourceAPI || execution.ToolVersion != "3.1.0" {
+		t.Errorf(`expected a different execution, but got %v`, execution)
+	}
+}
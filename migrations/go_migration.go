@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/iancoleman/strcase"
+)
+
+/*
+Go migrations:
+	Changes that cannot be expressed as static SQL (i.e. data backfills, or calls to external services)
+	can be written in Go and registered by binaries built around this package:
+
+		func init() {
+			migrations.Register("20230102150405000000", "BackfillSlugs", backfillSlugs, clearSlugs)
+		}
+
+	Registered migrations are ordered, validated, and tracked together with the migration files. See `BuildMigrations`.
+*/
+
+// MigrationFunc - The changes of a Go migration.
+// Unless the migration opts out of transactions, the store runs every statement inside the migration's transaction.
+type MigrationFunc func(store Store) error
+
+var (
+	GoMigrationVersionPattern = regexp.MustCompile(`^\d{20}$`)
+
+	registry = Migrations{}
+)
+
+// Register - Registers a Go migration. Typically called from the `init()` function of the file that defines it.
+func Register(version, name string, up, down MigrationFunc) {
+	registry = append(registry, Migration{Version: version, Name: name, UpFunc: up, DownFunc: down})
+}
+
+// RegisterNoTransaction - Registers a Go migration that runs outside of a transaction. See `Migration.DisableTransaction`.
+func RegisterNoTransaction(version, name string, up, down MigrationFunc) {
+	registry = append(registry, Migration{Version: version, Name: name, UpFunc: up, DownFunc: down, DisableTransaction: true})
+}
+
+// RegisteredMigrations - Returns the registered Go migrations, ordered by version.
+func RegisteredMigrations() Migrations {
+	registered := append(Migrations{}, registry...)
+	sort.Sort(registered)
+
+	return registered
+}
+
+// IsGo - Indicates that the changes of the migration are Go functions rather than SQL statements.
+func (M Migration) IsGo() bool {
+	return M.UpFunc != nil || M.DownFunc != nil
+}
+
+func validateGoMigration(migration Migration) (bool, string) {
+	if !GoMigrationVersionPattern.MatchString(migration.Version) {
+		return invalidMigration(migration, "invalid version (expected 20 digits)")
+	}
+
+	if migration.Name == "" || strcase.ToCamel(migration.Name) != migration.Name {
+		return invalidMigration(migration, "invalid name (expected CamelCase)")
+	}
+
+	if migration.UpFunc == nil || migration.DownFunc == nil {
+		return invalidMigration(migration, "missing up or down function")
+	}
+
+	return true, ""
+}
@@ -0,0 +1,170 @@
+package migrations
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func resetRegistry() {
+	registry = Migrations{}
+}
+
+func createLikes(store Store) error {
+	return store.Create("CREATE TABLE likes (id SERIAL);")
+}
+
+func dropLikes(store Store) error {
+	return store.Delete("DROP TABLE likes;")
+}
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(resetRegistry)
+
+	Register("20221231054550000000", "CreateLikes", createLikes, dropLikes)
+	RegisterNoTransaction("20221231054540000000", "BackfillLikes", createLikes, dropLikes)
+
+	registered := RegisteredMigrations()
+
+	if registered.Len() != 2 {
+		t.Fatalf(`expected 2 migrations, but got %v`, registered.Len())
+	}
+
+	// NOTE: Ordered by version
+	if registered[0].Name != "BackfillLikes" || !registered[0].DisableTransaction || !registered[0].IsGo() {
+		t.Errorf(`expected a different migration, but got %v`, registered[0].Description())
+	}
+}
+
+func TestBuildMigrationsWithGoMigrations(t *testing.T) {
+	t.Cleanup(resetRegistry)
+
+	Register("20220504202430000000", "BackfillUsers", createLikes, dropLikes)
+
+	files := LoadFiles("../examples", &FilePattern)
+	list := BuildMigrations(files, "../examples", &FilePattern)
+
+	if list.Size() != len(files)+1 {
+		t.Fatalf(`expected %v migrations, but got %v`, len(files)+1, list.Size())
+	}
+
+	// NOTE: Go migrations are placed between files according to their version
+	if second := list.GetHead().Next(); second.Name != "BackfillUsers" {
+		t.Errorf(`expected BackfillUsers to be second, but got %v`, second.Name)
+	}
+}
+
+func TestValidateGoMigrations(t *testing.T) {
+	scenarios := []struct {
+		migration Migration
+		valid     bool
+	}{
+		{Migration{Version: "20221231054550000000", Name: "CreateLikes", UpFunc: createLikes, DownFunc: dropLikes}, true},
+		{Migration{Version: "20221231054550", Name: "CreateLikes", UpFunc: createLikes, DownFunc: dropLikes}, false},
+		{Migration{Version: "20221231054550000000", Name: "create_likes", UpFunc: createLikes, DownFunc: dropLikes}, false},
+		{Migration{Version: "20221231054550000000", Name: "CreateLikes", UpFunc: createLikes}, false},
+	}
+
+	for _, scenario := range scenarios {
+		list := MigrationList{}
+		migration := scenario.migration
+		list.Insert(&migration)
+
+		if valid, reason := Validate(list); valid != scenario.valid {
+			t.Errorf(`expected valid == %v for %v, but got %v (%v)`, scenario.valid, migration.Description(), valid, reason)
+		}
+	}
+}
+
+func TestRunnerUpAndDownWithGoMigrations(t *testing.T) {
+	runner := testRunner()
+
+	migration := Migration{Version: "20221231054550000000", Name: "CreateLikes", UpFunc: createLikes, DownFunc: dropLikes}
+
+	list := MigrationList{}
+	list.Insert(&migration)
+
+	// Scenario 1: Go migrations are applied and tracked
+	if err := runner.Up(list); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if !IsTracked(runner.store, "likes") || IsEmpty(runner.store, runner.schemaTable) {
+		t.Errorf(`expected migration to have been applied`)
+	}
+
+	// Scenario 2: Go migrations are reverted
+	if err := runner.Down(list); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if IsTracked(runner.store, "likes") || !IsEmpty(runner.store, runner.schemaTable) {
+		t.Errorf(`expected migration to have been reverted`)
+	}
+
+	// Scenario 3: Failed Go migrations are rolled back
+	failure := errors.New("encoder unavailable")
+
+	failing := migration
+	failing.UpFunc = func(store Store) error {
+		if err := createLikes(store); err != nil {
+			return err
+		}
+
+		return failure
+	}
+
+	list = MigrationList{}
+	list.Insert(&failing)
+
+	if err := runner.Up(list); !errors.Is(err, failure) {
+		t.Errorf(`expected %v, but got %v`, failure, err)
+	}
+
+	if IsTracked(runner.store, "likes") {
+		t.Errorf(`expected table 'likes' to have been rolled back`)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerPlanWithGoMigrations(t *testing.T) {
+	runner := testRunner()
+
+	called := func(store Store) error {
+		t.Errorf(`expected Go migrations not to be run while planning`)
+		return nil
+	}
+
+	migration := Migration{Version: "20221231054550000000", Name: "CreateLikes", UpFunc: called, DownFunc: called}
+
+	list := MigrationList{}
+	list.Insert(&migration)
+
+	// Scenario 1: Only the tracking of Go migrations is planned
+	plan, err := runner.PlanUp(list)
+
+	if err != nil || len(plan.Migrations) != 1 {
+		t.Fatalf(`expected 1 migration, but got %v (%v)`, plan, err)
+	}
+
+	if planned := plan.Migrations[0]; !planned.Go || len(planned.Statements) != 2 {
+		t.Errorf(`expected the tracking of a Go migration, but got %+v`, planned)
+	}
+
+	if !strings.Contains(plan.Description(), "(CreateLikes) [Go migration, not planned]") {
+		t.Errorf(`expected the migration not to be planned, but got %v`, plan.Description())
+	}
+
+	// Scenario 2: Reverting Go migrations is not run either
+	migration.UpFunc = createLikes
+	runner.Up(list)
+
+	migration.UpFunc = called
+
+	if plan, err = runner.PlanDown(list); err != nil || len(plan.Migrations) != 1 || !plan.Migrations[0].Go {
+		t.Errorf(`expected 1 Go migration, but got %v (%v)`, plan, err)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
@@ -3,8 +3,11 @@ package migrations
 import (
 	"fmt"
 	"io/fs"
-	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/iancoleman/strcase"
@@ -12,9 +15,7 @@ import (
 
 // MatchingFiles - Finds all files that statisfy a regex in the specified directory
 func MatchingFiles(dir string, pattern *regexp.Regexp) ([]fs.FileInfo, error) {
-	matches := []fs.FileInfo{}
-
-	files, err := ioutil.ReadDir(dir)
+	matches, err := MatchingFilesFS(DirFS(dir), pattern)
 
 	if err != nil {
 		fmt.Println(err)
@@ -23,34 +24,108 @@ func MatchingFiles(dir string, pattern *regexp.Regexp) ([]fs.FileInfo, error) {
 		return matches, err
 	}
 
-	for _, file := range files {
-		if pattern.MatchString(file.Name()) {
-			matches = append(matches, file)
+	return matches, nil
+}
+
+// MatchingFilesFS - Finds all files that statisfy a regex in the root of the file system.
+// Works with any file system (i.e. an OS directory, an `embed.FS`, or a `fstest.MapFS`).
+func MatchingFilesFS(fsys fs.FS, pattern *regexp.Regexp) ([]fs.FileInfo, error) {
+	matches := []fs.FileInfo{}
+
+	entries, err := fs.ReadDir(fsys, ".")
+
+	if err != nil {
+		return matches, err
+	}
+
+	for _, entry := range entries {
+		if !pattern.MatchString(entry.Name()) {
+			continue
 		}
+
+		file, err := entry.Info()
+
+		if err != nil {
+			return matches, err
+		}
+
+		matches = append(matches, file)
 	}
 
 	return matches, nil
 }
 
+// DirFS - The file system rooted at an OS directory. An empty directory refers to the working directory.
+func DirFS(dir string) fs.FS {
+	if dir == "" {
+		dir = "."
+	}
+
+	return os.DirFS(dir)
+}
+
 // BuildMigrations - Instantiate a list of migrations from the contents of the provided files. Accesses the filesystem.
+// Registered Go migrations are included, ordered by version.
 func BuildMigrations(files []fs.FileInfo, dir string, pattern *regexp.Regexp) MigrationList {
-	var migrations MigrationList
+	return newMigrationList(append(RegisteredMigrations(), loadMigrations(DirFS(dir), files, dir, pattern)...))
+}
 
-	for _, file := range files {
-		var mg Migration
+// BuildMigrationsFS - Instantiate a list of migrations from the contents of the provided files, read from the file system.
+// Registered Go migrations are included, ordered by version.
+func BuildMigrationsFS(fsys fs.FS, files []fs.FileInfo, pattern *regexp.Regexp) MigrationList {
+	return newMigrationList(append(RegisteredMigrations(), loadMigrations(fsys, files, "", pattern)...))
+}
 
-		err := mg.Load(file, dir, pattern)
+// LoadMigrations - Instantiate a list of the migrations found in any of the directories (or their subdirectories).
+// Migrations from every directory, as well as registered Go migrations, are merged and ordered by version.
+func LoadMigrations(directories []string, pattern *regexp.Regexp) MigrationList {
+	loaded := RegisteredMigrations()
 
-		if err == nil {
-			migrations.Insert(&mg)
-		}
+	for _, dir := range directories {
+		fsys := DirFS(dir)
+		loaded = append(loaded, loadMigrations(fsys, LoadFilesFS(fsys, pattern), dir, pattern)...)
 	}
 
-	return migrations
+	return newMigrationList(loaded)
 }
 
 func LoadFiles(dir string, pattern *regexp.Regexp) []fs.FileInfo {
-	files, err := MatchingFiles(dir, pattern)
+	return LoadFilesFS(DirFS(dir), pattern)
+}
+
+// LoadFilesFS - Returns the files in the file system (and its subdirectories) that match the pattern.
+// The name of each file is its path relative to the root of the file system (i.e. `billing/20230102150405000000_create_invoices.yaml`).
+func LoadFilesFS(fsys fs.FS, pattern *regexp.Regexp) []fs.FileInfo {
+	files := []fs.FileInfo{}
+
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// NOTE: Hidden directories (i.e. `.git`) are skipped
+		if entry.IsDir() {
+			if name != "." && strings.HasPrefix(entry.Name(), ".") {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !pattern.MatchString(entry.Name()) {
+			return nil
+		}
+
+		info, err := entry.Info()
+
+		if err != nil {
+			return err
+		}
+
+		files = append(files, MigrationFile{name: name, size: info.Size(), mode: info.Mode(), modTime: info.ModTime(), sys: info.Sys()})
+
+		return nil
+	})
 
 	if err != nil {
 		return []fs.FileInfo{}
@@ -59,10 +134,42 @@ func LoadFiles(dir string, pattern *regexp.Regexp) []fs.FileInfo {
 	return files
 }
 
+// loadMigrations - Loads the migrations of the provided files. Their sources are relative to the directory.
+func loadMigrations(fsys fs.FS, files []fs.FileInfo, dir string, pattern *regexp.Regexp) Migrations {
+	loaded := Migrations{}
+
+	for _, file := range files {
+		var mg Migration
+
+		err := mg.LoadFS(fsys, file, pattern)
+
+		if err == nil {
+			mg.Path = path.Join(filepath.ToSlash(dir), file.Name())
+			loaded = append(loaded, mg)
+		}
+	}
+
+	return loaded
+}
+
+// newMigrationList - Instantiate a list of migrations ordered by version.
+// Migrations that share a version keep their relative order, so duplicates can be reported by `Validate()`.
+func newMigrationList(loaded Migrations) MigrationList {
+	var migrations MigrationList
+
+	sort.Stable(loaded)
+
+	for index := range loaded {
+		migrations.Insert(&loaded[index])
+	}
+
+	return migrations
+}
+
 // Validate - Runs validations on a list of migrations.
 func Validate(migrations MigrationList) (bool, string) {
-	visitedNames := map[string]bool{}
-	visitedVersions := map[string]bool{}
+	visitedNames := map[string]Migration{}
+	visitedVersions := map[string]Migration{}
 
 	migration := migrations.head
 
@@ -70,12 +177,24 @@ func Validate(migrations MigrationList) (bool, string) {
 		mismatchedInstructions := 0
 		mismatchedTables := map[string]string{}
 
-		if visitedVersions[migration.Version] {
-			return invalidMigration(*migration, "duplicate migration version")
+		if visited, found := visitedVersions[migration.Version]; found {
+			return invalidMigration(*migration, duplicateReason("version", visited))
 		}
 
-		if visitedNames[migration.Name] {
-			return invalidMigration(*migration, "duplicate migration name")
+		if visited, found := visitedNames[migration.Name]; found {
+			return invalidMigration(*migration, duplicateReason("name", visited))
+		}
+
+		if migration.IsGo() {
+			if valid, reason := validateGoMigration(*migration); !valid {
+				return valid, reason
+			}
+
+			visitedNames[migration.Name] = *migration
+			visitedVersions[migration.Version] = *migration
+
+			migration = migration.next
+			continue
 		}
 
 		// TODO: Check if migration is using a supported engine
@@ -127,8 +246,8 @@ func Validate(migrations MigrationList) (bool, string) {
 			return invalidMigration(*migration, "name mismatch")
 		}
 
-		visitedNames[migration.Name] = true
-		visitedVersions[migration.Version] = true
+		visitedNames[migration.Name] = *migration
+		visitedVersions[migration.Version] = *migration
 
 		migration = migration.next
 	}
@@ -154,6 +273,15 @@ func checkForMatchingCreateAndDropInstructions(change string, mismatchedTables m
 	return mismatchedInstructions
 }
 
+// duplicateReason - Explains which migration was found first. Migrations often share a version across directories.
+func duplicateReason(attribute string, visited Migration) string {
+	if visited.Path == "" {
+		return fmt.Sprintf("duplicate migration %v", attribute)
+	}
+
+	return fmt.Sprintf("duplicate migration %v (also in %v)", attribute, visited.Path)
+}
+
 func invalidMigration(migration Migration, reason string) (bool, string) {
 	return false, fmt.Sprintf("Invalid migration: %v. Reason: %v.", migration.Description(), reason)
 }
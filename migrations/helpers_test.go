@@ -2,12 +2,28 @@ package migrations
 
 import (
 	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
 var list = MigrationList{}
 
+func defaultMigrationFS() fstest.MapFS {
+	return fstest.MapFS{
+		"db/migrations/20221231054530129328_create_users.yaml": {
+			Data: []byte("engine: postgresql\nname: CreateUsers\nchanges:\n  up:\n    - CREATE TABLE users (id SERIAL);\n  down:\n    - DROP TABLE users;\n"),
+		},
+		"db/migrations/20221231054531293821_create_articles.sql": {
+			Data: []byte("-- +dm Engine postgresql\n\n-- +dm Up\nCREATE TABLE articles (id SERIAL);\n\n-- +dm Down\nDROP TABLE articles;\n"),
+		},
+		"db/migrations/README.md": {Data: []byte("# Migrations")},
+	}
+}
+
 func defaultMigrationList() MigrationList {
 	res := MigrationList{}
 
@@ -276,8 +292,8 @@ func TestMatchingFilesEmpty(t *testing.T) {
 func TestMatchingFiles(t *testing.T) {
 	matchedFiles, _ := MatchingFiles("../examples", &FilePattern)
 
-	if len(matchedFiles) != 6 {
-		t.Fatalf(`want len(matches) == 6, but got %v`, len(matchedFiles))
+	if len(matchedFiles) != 7 {
+		t.Fatalf(`want len(matches) == 7, but got %v`, len(matchedFiles))
 	}
 }
 
@@ -299,6 +315,42 @@ func TestLoadFiles(t *testing.T) {
 	}
 }
 
+func TestLoadFilesFS(t *testing.T) {
+	fsys, _ := fs.Sub(defaultMigrationFS(), "db/migrations")
+
+	files := LoadFilesFS(fsys, &FilePattern)
+
+	if len(files) != 2 {
+		t.Fatalf(`want size == 2, but got %v`, len(files))
+	}
+
+	// Scenario 2: Missing directory
+	missing, _ := fs.Sub(defaultMigrationFS(), "db/seeds")
+
+	if files = LoadFilesFS(missing, &FilePattern); len(files) != 0 {
+		t.Fatalf(`want size == 0, but got %v`, len(files))
+	}
+}
+
+func TestLoadFilesFSRecursively(t *testing.T) {
+	fsys := fstest.MapFS{
+		"auth/20221231054530129328_create_users.yaml":               {Data: []byte("name: CreateUsers")},
+		"billing/invoices/20221231054531293821_create_invoices.sql": {Data: []byte("-- +dm Up")},
+		".git/20221231054532123874_create_comments.yaml":            {Data: []byte("name: CreateComments")},
+	}
+
+	files := LoadFilesFS(fsys, &FilePattern)
+
+	if len(files) != 2 {
+		t.Fatalf(`want size == 2, but got %v`, len(files))
+	}
+
+	// NOTE: Files are named after their path
+	if files[1].Name() != "billing/invoices/20221231054531293821_create_invoices.sql" {
+		t.Errorf(`expected a different file, but got %v`, files[1].Name())
+	}
+}
+
 // MARK: Migration Builder
 
 func TestBuildMigrationsEmpty(t *testing.T) {
@@ -324,3 +376,57 @@ func TestBuildMigrations(t *testing.T) {
 		t.Fatalf(`want size == 3, but got %v`, list.Size())
 	}
 }
+
+func TestBuildMigrationsFS(t *testing.T) {
+	fsys, _ := fs.Sub(defaultMigrationFS(), "db/migrations")
+
+	list = BuildMigrationsFS(fsys, LoadFilesFS(fsys, &FilePattern), &FilePattern)
+
+	if list.Size() != 2 {
+		t.Fatalf(`want size == 2, but got %v`, list.Size())
+	}
+
+	if tail := list.GetTail(); tail.Name != "CreateArticles" || len(tail.Changes.Down) != 1 {
+		t.Errorf(`expected a different migration, but got %v`, tail.Description())
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	root := t.TempDir()
+
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	write("auth/20221231054530129328_create_users.yaml", "engine: postgresql\nname: CreateUsers\nchanges:\n  up:\n    - CREATE TABLE users (id SERIAL);\n  down:\n    - DROP TABLE users;\n")
+	write("billing/20221231054531293821_create_invoices.yaml", "engine: postgresql\nname: CreateInvoices\nchanges:\n  up:\n    - CREATE TABLE invoices (id SERIAL);\n  down:\n    - DROP TABLE invoices;\n")
+	write("billing/refunds/20221231054520000000_create_refunds.yaml", "engine: postgresql\nname: CreateRefunds\nchanges:\n  up:\n    - CREATE TABLE refunds (id SERIAL);\n  down:\n    - DROP TABLE refunds;\n")
+
+	directories := []string{filepath.Join(root, "billing"), filepath.Join(root, "auth")}
+
+	// Scenario 1: Migrations from every directory are ordered by version
+	list = LoadMigrations(directories, &FilePattern)
+
+	if list.Size() != 3 || list.GetHead().Name != "CreateRefunds" || list.GetTail().Name != "CreateInvoices" {
+		t.Fatalf(`expected 3 ordered migrations, but got %v`, list.ToSlice().Description())
+	}
+
+	if path := filepath.ToSlash(filepath.Join(root, "billing/refunds/20221231054520000000_create_refunds.yaml")); list.GetHead().Path != path {
+		t.Errorf(`expected path %v, but got %v`, path, list.GetHead().Path)
+	}
+
+	if valid, reason := Validate(list); !valid {
+		t.Errorf(`expected migrations to be valid, but got %v`, reason)
+	}
+
+	// Scenario 2: Duplicate versions across directories
+	write("auth/20221231054531293821_create_sessions.yaml", "engine: postgresql\nname: CreateSessions\nchanges:\n  up:\n    - CREATE TABLE sessions (id SERIAL);\n  down:\n    - DROP TABLE sessions;\n")
+
+	list = LoadMigrations(directories, &FilePattern)
+
+	if valid, reason := Validate(list); valid || !strings.Contains(reason, "duplicate migration version (also in") {
+		t.Errorf(`expected a duplicate version, but got %v`, reason)
+	}
+}
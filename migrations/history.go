@@ -0,0 +1,148 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+)
+
+/*
+History:
+	Every change to the schema table is also appended to a history table (`<table>_history`).
+	Entries are never updated or removed, so the history outlives rollbacks.
+*/
+
+const (
+	HistoryApply          = "apply"
+	HistoryRevert         = "revert"
+	HistoryApplyFailure   = "apply_failure"
+	HistoryRevertFailure  = "revert_failure"
+	HistoryBaseline       = "baseline"
+	HistoryRepair         = "repair"
+	DefaultHistoryEntries = 100
+)
+
+var HistoryActions = []string{
+	HistoryApply,
+	HistoryRevert,
+	HistoryApplyFailure,
+	HistoryRevertFailure,
+	HistoryBaseline,
+	HistoryRepair,
+}
+
+type HistoryEntry struct {
+	Id        int       `json:"id"`
+	Version   string    `json:"version"`
+	Name      string    `json:"name"`
+	Action    string    `json:"action"`
+	Error     string    `json:"error,omitempty" db:"error_message"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	Execution
+}
+
+type History []HistoryEntry
+
+// HistoryFilter - Narrows down the entries returned by `Runner.History()`. Zero values are ignored.
+type HistoryFilter struct {
+	// Migration - The name or version of a migration.
+	Migration string
+	Action    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+func (H HistoryEntry) Description() string {
+	description := fmt.Sprintf(
+		"%v %-14v Version: %v (%v) by %v@%v via %v in %vms",
+		H.CreatedAt.Format(time.RFC3339),
+		H.Action,
+		H.Version,
+		H.Name,
+		H.AppliedBy,
+		H.Host,
+		H.Source,
+		H.DurationMs,
+	)
+
+	if H.Error != "" {
+		description += fmt.Sprintf("\n  Error: %v", H.Error)
+	}
+
+	return description
+}
+
+func (H History) Description() string {
+	if len(H) == 0 {
+		return "No history"
+	}
+
+	description := ""
+
+	for _, entry := range H {
+		description += fmt.Sprintln(entry.Description())
+	}
+
+	return description
+}
+
+// History - Returns the history entries that match the filter, most recent first.
+func (runner *Runner) History(filter HistoryFilter) (History, error) {
+	runner.beforeAction()
+
+	history := History{}
+
+	if !IsTracked(runner.store, HistoryTable(runner.schemaTable)) {
+		return history, nil
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryEntries
+	}
+
+	query, args := SelectHistoryEntries(DialectOf(runner.store), runner.schemaTable, filter)
+
+	err := runner.store.Read(query, &history, args...)
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("Unable to read history.\nError: %v\n", err))
+		return History{}, err
+	}
+
+	return history, nil
+}
+
+// record - Appends an entry to the history table. History is kept on a best-effort basis,
+// so failing to record an entry does not fail the action being recorded.
+func (runner *Runner) record(migration Migration, action string, failure error) {
+	err := runner.recordHistory(runner.store, migration, action, failure)
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("Unable to record '%v' of %v in history.\nError: %v\n", action, migration.Version, err))
+	}
+}
+
+// recordHistory - Appends an entry to the history table. Failures are recorded with the text of the error.
+func (runner *Runner) recordHistory(store Store, migration Migration, action string, failure error) error {
+	execution := runner.execution()
+
+	var message interface{}
+
+	if failure != nil {
+		message = failure.Error()
+	}
+
+	return store.Create(
+		CreateHistoryEntry(DialectOf(store), runner.schemaTable),
+		migration.Version,
+		migration.Name,
+		action,
+		message,
+		migration.DurationMs,
+		execution.AppliedBy,
+		execution.Host,
+		execution.ToolVersion,
+		execution.Source,
+	)
+}
@@ -0,0 +1,132 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryDescription(t *testing.T) {
+	// Scenario 1: No entries
+	if (History{}).Description() != "No history" {
+		t.Errorf(`expected a different description, got %v`, History{}.Description())
+	}
+
+	// Scenario 2: A failed entry
+	entry := HistoryEntry{
+		Version:   "20220504202422742293",
+		Name:      "CreateUsers",
+		Action:    HistoryApplyFailure,
+		Error:     "relation \"users\" already exists",
+		CreatedAt: time.Date(2022, 5, 4, 20, 24, 22, 0, time.UTC),
+		Execution: Execution{DurationMs: 3, AppliedBy: "deploy", Host: "web-1", Source: SourceCLI},
+	}
+
+	description := "2022-05-04T20:24:22Z apply_failure  Version: 20220504202422742293 (CreateUsers) by deploy@web-1 via cli in 3ms\n" +
+		"  Error: relation \"users\" already exists"
+
+	if entry.Description() != description {
+		t.Errorf(`expected a different description, got %v`, entry.Description())
+	}
+}
+
+func TestRunnerHistory(t *testing.T) {
+	runner := testRunner()
+
+	// Scenario 1: An untracked database
+	history, err := runner.History(HistoryFilter{})
+
+	if err != nil || len(history) != 0 {
+		t.Fatalf(`expected no history, but got %v (%v)`, history, err)
+	}
+
+	// Scenario 2: Migrations and rollbacks are recorded
+	runner.Up(defaultMigrationList())
+
+	list := defaultMigrationList()
+	list.Reverse()
+	runner.Down(list)
+
+	history, err = runner.History(HistoryFilter{})
+
+	if err != nil || len(history) != 6 {
+		t.Fatalf(`expected 6 entries, but got %v (%v)`, len(history), err)
+	}
+
+	// NOTE: Most recent first
+	if history[0].Action != HistoryRevert || history[0].Version != list.GetTail().Version {
+		t.Errorf(`expected the last rollback first, but got %v`, history[0].Description())
+	}
+
+	if history[5].Action != HistoryApply || history[5].AppliedBy != CurrentActor() {
+		t.Errorf(`expected the first migration last, but got %v`, history[5].Description())
+	}
+
+	// Scenario 3: Filtered by migration and action
+	history, _ = runner.History(HistoryFilter{Migration: "CreateUsers", Action: HistoryApply})
+
+	if len(history) != 1 || history[0].Name != "CreateUsers" {
+		t.Errorf(`expected 1 entry, but got %v`, history.Description())
+	}
+
+	// Scenario 4: Filtered by time range
+	history, _ = runner.History(HistoryFilter{Since: time.Now().Add(time.Hour)})
+
+	if len(history) != 0 {
+		t.Errorf(`expected no entries, but got %v`, history.Description())
+	}
+
+	history, _ = runner.History(HistoryFilter{Since: time.Now().Add(-time.Hour), Until: time.Now().Add(time.Hour), Limit: 2})
+
+	if len(history) != 2 {
+		t.Errorf(`expected 2 entries, but got %v`, len(history))
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerHistoryRecordsFailures(t *testing.T) {
+	runner := testRunner()
+
+	migration := Migration{
+		Version:  "20221231054540000000",
+		Engine:   "postgresql",
+		Name:     "CreateLikes",
+		FileName: "20221231054540000000_create_likes.yaml",
+		Changes: Changes{
+			Up:   []string{"CREATE TABLE likes (id SERIAL);", "INSERT INTO missing_table VALUES (1);"},
+			Down: []string{"DROP TABLE likes;"},
+		},
+	}
+
+	var list MigrationList
+	list.Insert(&migration)
+
+	err := runner.Up(list)
+
+	if err == nil {
+		t.Fatalf(`expected an error, but got nil`)
+	}
+
+	history, _ := runner.History(HistoryFilter{Migration: "CreateLikes"})
+
+	if len(history) != 1 || history[0].Action != HistoryApplyFailure || !strings.Contains(history[0].Error, "missing_table") {
+		t.Errorf(`expected a failed entry, but got %v`, history.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerHistoryRecordsBaselines(t *testing.T) {
+	runner := testRunner()
+
+	runner.Baseline(defaultMigrationList())
+
+	history, _ := runner.History(HistoryFilter{Action: HistoryBaseline})
+
+	if len(history) != 3 {
+		t.Errorf(`expected 3 entries, but got %v`, history.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
@@ -0,0 +1,594 @@
+package migrations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v2"
+)
+
+/*
+Importing:
+	Migrations written for other tools can be converted into migration files. The version of each
+	source migration is mapped to a 20-digit version that preserves the original order:
+
+		golang-migrate, goose, rails:  20190101120000 -> 20190101120000000000 (timestamps)
+		                               42             -> 00000000000000000042
+		flyway:                        1.2.3          -> 00000000010000200003
+
+	The migrations applied by the tool can then be copied from its schema table into the schema table
+	used by the runner, so that the database is recognized as migrated.
+*/
+
+type ImportSource string
+
+const (
+	GolangMigrate ImportSource = "golang-migrate"
+	Goose         ImportSource = "goose"
+	Flyway        ImportSource = "flyway"
+	Rails         ImportSource = "rails"
+)
+
+const GooseDirectivePrefix = "-- +goose"
+
+var (
+	GolangMigrateFilePattern = regexp.MustCompile(`^(?P<Version>\d+)_(?P<Name>.+)\.(?P<Direction>up|down)\.sql$`)
+	GooseFilePattern         = regexp.MustCompile(`^(?P<Version>\d+)_(?P<Name>.+)\.(?P<Extension>sql|go)$`)
+	FlywayFilePattern        = regexp.MustCompile(`^(?P<Prefix>[VUR])(?P<Version>\d+(?:[._]\d+)*)?__(?P<Name>.+)\.sql$`)
+	RailsFilePattern         = regexp.MustCompile(`^(?P<Version>\d+)_(?P<Name>\w+)\.rb$`)
+
+	RailsMethodPattern    = regexp.MustCompile(`^def\s+(up|down|change)\b`)
+	RailsHeredocPattern   = regexp.MustCompile(`^execute\s*\(?\s*<<[~-]?['"]?(\w+)['"]?`)
+	RailsStatementPattern = regexp.MustCompile(`^execute\s*\(?\s*(?:"([^"#]*)"|'([^']*)')\s*\)?$`)
+
+	ImportNameBoundaryPattern  = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+	ImportNameExclusionPattern = regexp.MustCompile(`[^a-z0-9]+`)
+	ImportNameLetterPattern    = regexp.MustCompile(`[a-z]`)
+
+	// ImportSchemaTables - The schema tables used by each supported tool
+	ImportSchemaTables = map[ImportSource]string{
+		GolangMigrate: "schema_migrations",
+		Goose:         "goose_db_version",
+		Flyway:        "flyway_schema_history",
+		Rails:         "schema_migrations",
+	}
+
+	importPatterns = map[ImportSource]*regexp.Regexp{
+		GolangMigrate: GolangMigrateFilePattern,
+		Goose:         GooseFilePattern,
+		Flyway:        FlywayFilePattern,
+		Rails:         RailsFilePattern,
+	}
+
+	importReaders = map[ImportSource]importReader{
+		GolangMigrate: readGolangMigrateFile,
+		Goose:         readGooseFile,
+		Flyway:        readFlywayFile,
+		Rails:         readRailsFile,
+	}
+)
+
+// importReader - Populates a migration from the contents of a file matched by the source's file pattern.
+// Returns a non-empty reason if the file cannot be imported.
+type importReader func(groups map[string]string, contents []byte, migration *Migration) (skip string, err error)
+
+type ImportedMigration struct {
+	Migration     Migration `json:"migration"`
+	SourceVersion string    `json:"source_version"`
+	Files         []string  `json:"files"`
+}
+
+type SkippedFile struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+type Import struct {
+	Source     ImportSource        `json:"source"`
+	Migrations []ImportedMigration `json:"migrations"`
+	Skipped    []SkippedFile       `json:"skipped,omitempty"`
+}
+
+func (I Import) Description() string {
+	description := fmt.Sprintf("Imported %v migration(s) from %v\n", len(I.Migrations), I.Source)
+
+	for _, imported := range I.Migrations {
+		description += fmt.Sprintf("%v <- %v\n", imported.Migration.Description(), strings.Join(imported.Files, ", "))
+	}
+
+	for _, skipped := range I.Skipped {
+		description += fmt.Sprintf("Skipped %v: %v\n", skipped.File, skipped.Reason)
+	}
+
+	return description
+}
+
+// ReadImport - Converts the migration files of another tool found in the directory.
+func ReadImport(source ImportSource, directory, engine string) (Import, error) {
+	imported := Import{Source: source, Migrations: []ImportedMigration{}}
+
+	pattern, supported := importPatterns[source]
+
+	if !supported {
+		return imported, ImportError{Reason: fmt.Sprintf("unsupported source '%v'", source)}
+	}
+
+	files, err := MatchingFiles(directory, pattern)
+
+	if err != nil {
+		return imported, err
+	}
+
+	indexed := map[string]*ImportedMigration{}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		groups := submatches(pattern, file.Name())
+
+		contents, err := ioutil.ReadFile(filepath.Join(directory, file.Name()))
+
+		if err != nil {
+			return imported, err
+		}
+
+		partial := Migration{Engine: engine}
+
+		skip, err := importReaders[source](groups, contents, &partial)
+
+		if err != nil {
+			return imported, ImportError{File: file.Name(), Reason: err.Error()}
+		}
+
+		if skip != "" {
+			imported.Skipped = append(imported.Skipped, SkippedFile{File: file.Name(), Reason: skip})
+			continue
+		}
+
+		key, err := importVersionKey(groups["Version"])
+
+		if err != nil {
+			return imported, ImportError{File: file.Name(), Reason: err.Error()}
+		}
+
+		entry, found := indexed[key]
+
+		if !found {
+			version, err := importVersion(source, groups["Version"])
+
+			if err != nil {
+				return imported, ImportError{File: file.Name(), Reason: err.Error()}
+			}
+
+			name := importName(groups["Name"])
+
+			entry = &ImportedMigration{
+				SourceVersion: key,
+				Migration: Migration{
+					Schema:   2,
+					Engine:   engine,
+					Name:     strcase.ToCamel(name),
+					Version:  version,
+					FileName: fmt.Sprintf("%v_%v.yaml", version, name),
+				},
+			}
+
+			indexed[key] = entry
+		}
+
+		entry.Files = append(entry.Files, file.Name())
+		entry.Migration.Changes.Up = append(entry.Migration.Changes.Up, partial.Changes.Up...)
+		entry.Migration.Changes.Down = append(entry.Migration.Changes.Down, partial.Changes.Down...)
+		entry.Migration.DisableTransaction = entry.Migration.DisableTransaction || partial.DisableTransaction
+	}
+
+	for _, entry := range indexed {
+		// NOTE: Files that only revert a migration cannot be imported on their own
+		if len(entry.Migration.Changes.Up) == 0 {
+			for _, file := range entry.Files {
+				imported.Skipped = append(imported.Skipped, SkippedFile{File: file, Reason: "no up migration found"})
+			}
+
+			continue
+		}
+
+		imported.Migrations = append(imported.Migrations, *entry)
+	}
+
+	sort.Slice(imported.Migrations, func(i, j int) bool {
+		return imported.Migrations[i].Migration.Version < imported.Migrations[j].Migration.Version
+	})
+
+	sort.Slice(imported.Skipped, func(i, j int) bool {
+		return imported.Skipped[i].File < imported.Skipped[j].File
+	})
+
+	names := map[string]string{}
+
+	for _, entry := range imported.Migrations {
+		if file, found := names[entry.Migration.Name]; found {
+			return imported, ImportError{
+				File:   entry.Files[0],
+				Reason: fmt.Sprintf("migration name '%v' is also used by %v", entry.Migration.Name, file),
+			}
+		}
+
+		names[entry.Migration.Name] = entry.Files[0]
+	}
+
+	return imported, nil
+}
+
+// Write - Saves the imported migrations as YAML files. Existing files are left untouched.
+// Returns the names of the files that were written.
+func (I Import) Write(directory string) ([]string, error) {
+	written := []string{}
+
+	for _, imported := range I.Migrations {
+		path := filepath.Join(directory, imported.Migration.FileName)
+
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+
+		content, err := yaml.Marshal(&imported.Migration)
+
+		if err != nil {
+			return written, err
+		}
+
+		err = ioutil.WriteFile(path, content, 0644)
+
+		if err != nil {
+			return written, err
+		}
+
+		written = append(written, imported.Migration.FileName)
+	}
+
+	return written, nil
+}
+
+// ImportHistory - Records the imported migrations that were applied by the source tool in the schema table.
+// Returns the migrations that were recorded.
+func (runner *Runner) ImportHistory(imported Import, sourceTable string) (Migrations, error) {
+	recorded := Migrations{}
+
+	versions, err := importedVersions(runner.store, imported, sourceTable)
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("Unable to read the history of %v from %v.\nError: %v\n", imported.Source, sourceTable, err))
+		return recorded, err
+	}
+
+	release, err := runner.lock()
+
+	if err != nil {
+		return recorded, err
+	}
+
+	defer release()
+
+	if !IsTracked(runner.store, runner.schemaTable) && !StartTracking(runner.store, runner.schemaTable) {
+		return recorded, new(EngineError)
+	}
+
+	runner.beforeAction()
+
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+	registered := applied.ToMap()
+
+	for _, entry := range imported.Migrations {
+		_, found := registered[entry.Migration.Version]
+
+		if versions[entry.SourceVersion] && !found {
+			recorded = append(recorded, entry.Migration)
+		}
+	}
+
+	err = runner.store.Transaction(func(store Store) error {
+		for _, migration := range recorded {
+			if err := runner.registerMigration(store, migration, runner.schemaTable); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("Unable to record imported migrations.\nError: %v\n", err))
+		return Migrations{}, err
+	}
+
+	return recorded, nil
+}
+
+// MARK: - Source history
+
+type golangMigrateVersion struct {
+	Version int64 `db:"version"`
+	Dirty   bool  `db:"dirty"`
+}
+
+type gooseVersion struct {
+	VersionId int64 `db:"version_id"`
+	IsApplied bool  `db:"is_applied"`
+}
+
+type flywayVersion struct {
+	Version string `db:"version"`
+	Type    string `db:"type"`
+	Success bool   `db:"success"`
+}
+
+// importedVersions - Returns the (normalized) source versions applied according to the schema table of the source tool.
+func importedVersions(store Store, imported Import, table string) (map[string]bool, error) {
+	applied := map[string]bool{}
+
+	switch imported.Source {
+	case GolangMigrate:
+		// NOTE: golang-migrate only stores the current version
+		var versions []golangMigrateVersion
+
+		if err := store.Read(SelectGolangMigrateVersion(table), &versions); err != nil || len(versions) == 0 {
+			return applied, err
+		}
+
+		if versions[0].Dirty {
+			return applied, ImportError{Reason: fmt.Sprintf("%v is dirty at version %v", table, versions[0].Version)}
+		}
+
+		for _, entry := range imported.Migrations {
+			version, err := strconv.ParseInt(entry.SourceVersion, 10, 64)
+
+			applied[entry.SourceVersion] = err == nil && version <= versions[0].Version
+		}
+	case Goose:
+		// NOTE: The most recent entry of a version determines whether it is applied
+		var versions []gooseVersion
+
+		if err := store.Read(SelectGooseVersions(table), &versions); err != nil {
+			return applied, err
+		}
+
+		for _, version := range versions {
+			applied[strconv.FormatInt(version.VersionId, 10)] = version.IsApplied
+		}
+	case Flyway:
+		var versions []flywayVersion
+
+		if err := store.Read(SelectFlywayVersions(table), &versions); err != nil {
+			return applied, err
+		}
+
+		for _, version := range versions {
+			key, err := importVersionKey(version.Version)
+
+			if err != nil || !version.Success {
+				continue
+			}
+
+			applied[key] = version.Type != "UNDO_SQL"
+		}
+	case Rails:
+		var versions []string
+
+		if err := store.Read(SelectRailsVersions(table), &versions); err != nil {
+			return applied, err
+		}
+
+		for _, version := range versions {
+			if key, err := importVersionKey(version); err == nil {
+				applied[key] = true
+			}
+		}
+	default:
+		return applied, ImportError{Reason: fmt.Sprintf("unsupported source '%v'", imported.Source)}
+	}
+
+	return applied, nil
+}
+
+// MARK: - Source files
+
+func readGolangMigrateFile(groups map[string]string, contents []byte, migration *Migration) (string, error) {
+	if groups["Direction"] == "up" {
+		migration.Changes.Up = SplitStatements(migration.dialect(), string(contents))
+	} else {
+		migration.Changes.Down = SplitStatements(migration.dialect(), string(contents))
+	}
+
+	return "", nil
+}
+
+func readGooseFile(groups map[string]string, contents []byte, migration *Migration) (string, error) {
+	if groups["Extension"] == "go" {
+		return "Go migrations cannot be imported", nil
+	}
+
+	return "", migration.parseSQL(contents, GooseDirectivePrefix)
+}
+
+func readFlywayFile(groups map[string]string, contents []byte, migration *Migration) (string, error) {
+	switch groups["Prefix"] {
+	case "R":
+		return "repeatable migrations cannot be imported", nil
+	case "U":
+		migration.Changes.Down = SplitStatements(migration.dialect(), string(contents))
+	default:
+		migration.Changes.Up = SplitStatements(migration.dialect(), string(contents))
+	}
+
+	return "", nil
+}
+
+// readRailsFile - Extracts the SQL run through `execute` in the `up`, `down`, and `change` methods of a Rails migration.
+// Migrations written with the rest of the Rails DSL cannot be imported.
+func readRailsFile(groups map[string]string, contents []byte, migration *Migration) (string, error) {
+	var section *[]string
+	var heredoc string
+	var body strings.Builder
+
+	for _, line := range strings.Split(string(contents), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if heredoc != "" {
+			if trimmed == heredoc {
+				*section = append(*section, SplitStatements(migration.dialect(), body.String())...)
+				heredoc = ""
+				body.Reset()
+			} else {
+				body.WriteString(line + "\n")
+			}
+
+			continue
+		}
+
+		switch {
+		case trimmed == "" || trimmed == "end" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "class ") || strings.HasPrefix(trimmed, "require"):
+			continue
+		case RailsMethodPattern.MatchString(trimmed):
+			if RailsMethodPattern.FindStringSubmatch(trimmed)[1] == "down" {
+				section = &migration.Changes.Down
+			} else {
+				section = &migration.Changes.Up
+			}
+		case section != nil && RailsHeredocPattern.MatchString(trimmed):
+			heredoc = RailsHeredocPattern.FindStringSubmatch(trimmed)[1]
+		case section != nil && RailsStatementPattern.MatchString(trimmed):
+			match := RailsStatementPattern.FindStringSubmatch(trimmed)
+			*section = append(*section, SplitStatements(migration.dialect(), match[1]+match[2])...)
+		default:
+			return "uses the Rails migration DSL", nil
+		}
+	}
+
+	if heredoc != "" {
+		return "", fmt.Errorf("missing heredoc terminator '%v'", heredoc)
+	}
+
+	if len(migration.Changes.Up) == 0 {
+		return "no SQL statements found", nil
+	}
+
+	return "", nil
+}
+
+// MARK: - Versions and names
+
+// importVersion - Maps the version of a source migration to a 20-digit version that preserves its order.
+func importVersion(source ImportSource, version string) (string, error) {
+	parts, err := importVersionParts(version)
+
+	if err != nil {
+		return "", err
+	}
+
+	if source == Flyway {
+		limits := []uint64{1e10, 1e5, 1e5}
+
+		if len(parts) > len(limits) {
+			return "", fmt.Errorf("version '%v' has more than %v parts", version, len(limits))
+		}
+
+		for len(parts) < len(limits) {
+			parts = append(parts, 0)
+		}
+
+		for i, part := range parts {
+			if part >= limits[i] {
+				return "", fmt.Errorf("version '%v' is out of range", version)
+			}
+		}
+
+		return fmt.Sprintf("%010d%05d%05d", parts[0], parts[1], parts[2]), nil
+	}
+
+	if len(parts) != 1 {
+		return "", fmt.Errorf("invalid version '%v'", version)
+	}
+
+	// NOTE: Timestamps (i.e. 20190101120000) are extended with fractional seconds
+	if len(version) == 14 {
+		return version + "000000", nil
+	}
+
+	return fmt.Sprintf("%020d", parts[0]), nil
+}
+
+// importVersionKey - Normalizes a source version (i.e. `0042` -> `42`, `1_1` -> `1.1`) so it can be matched against schema tables.
+func importVersionKey(version string) (string, error) {
+	parts, err := importVersionParts(version)
+
+	if err != nil {
+		return "", err
+	}
+
+	keys := []string{}
+
+	for _, part := range parts {
+		keys = append(keys, strconv.FormatUint(part, 10))
+	}
+
+	return strings.Join(keys, "."), nil
+}
+
+func importVersionParts(version string) ([]uint64, error) {
+	parts := []uint64{}
+
+	for _, field := range strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' }) {
+		part, err := strconv.ParseUint(field, 10, 64)
+
+		if err != nil {
+			return parts, fmt.Errorf("invalid version '%v'", version)
+		}
+
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		return parts, fmt.Errorf("invalid version '%v'", version)
+	}
+
+	return parts, nil
+}
+
+// importName - Converts the name of a source migration into a name accepted by `FilePattern` (letters, digits and underscores).
+// Digits are kept next to the letters they follow (i.e. `AddUsersV2` becomes `add_users_v2`, not `add_users_v_2`).
+// Names without letters are replaced, so they cannot be mistaken for versions.
+func importName(name string) string {
+	name = strings.ToLower(ImportNameBoundaryPattern.ReplaceAllString(name, "${1}_${2}"))
+	name = ImportNameExclusionPattern.ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+
+	if !ImportNameLetterPattern.MatchString(name) {
+		return "imported_migration"
+	}
+
+	return name
+}
+
+func submatches(pattern *regexp.Regexp, value string) map[string]string {
+	groups := map[string]string{}
+	match := pattern.FindStringSubmatch(value)
+
+	for i, group := range pattern.SubexpNames() {
+		if group != "" && i < len(match) {
+			groups[group] = match[i]
+		}
+	}
+
+	return groups
+}
@@ -0,0 +1,322 @@
+package migrations
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/oleoneto/dm/stores"
+)
+
+func TestReadImportGolangMigrate(t *testing.T) {
+	dir := importFixtures(t, map[string]string{
+		"1_create_users.up.sql":        "CREATE TABLE users (id SERIAL, name VARCHAR NOT NULL);\nINSERT INTO users (name) VALUES ('a;b');\n",
+		"1_create_users.down.sql":      "DROP TABLE users;\n",
+		"2_add-users-email.up.sql":     "ALTER TABLE users ADD COLUMN email VARCHAR;\n",
+		"3_remove_stuff.down.sql":      "ALTER TABLE users DROP COLUMN stuff;\n",
+		"README.md":                    "Not a migration",
+		"20221231054540_seed.up.sql":   "INSERT INTO users (name) VALUES ('seed');\n",
+		"20221231054540_seed.down.sql": "DELETE FROM users WHERE name = 'seed';\n",
+	})
+
+	imported, err := ReadImport(GolangMigrate, dir, "postgresql")
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if len(imported.Migrations) != 3 || len(imported.Skipped) != 1 {
+		t.Fatalf(`expected 3 migrations and 1 skipped file, but got %v and %v`, len(imported.Migrations), imported.Skipped)
+	}
+
+	users := imported.Migrations[0].Migration
+
+	if users.Version != "00000000000000000001" || users.Name != "CreateUsers" || users.FileName != "00000000000000000001_create_users.yaml" {
+		t.Errorf(`expected a different migration, but got %v (%v, %v)`, users.Version, users.Name, users.FileName)
+	}
+
+	if len(users.Changes.Up) != 2 || len(users.Changes.Down) != 1 || users.Engine != "postgresql" {
+		t.Errorf(`expected a different migration, but got %v (%v)`, users.Changes, users.Engine)
+	}
+
+	if imported.Migrations[1].Migration.Name != "AddUsersEmail" {
+		t.Errorf(`expected AddUsersEmail, but got %v`, imported.Migrations[1].Migration.Name)
+	}
+
+	if imported.Migrations[2].Migration.Version != "20221231054540000000" || imported.Migrations[2].SourceVersion != "20221231054540" {
+		t.Errorf(`expected timestamp to be extended, but got %v`, imported.Migrations[2].Migration.Version)
+	}
+}
+
+func TestReadImportGoose(t *testing.T) {
+	dir := importFixtures(t, map[string]string{
+		"00001_create_users.sql": "-- +goose Up\nCREATE TABLE users (id SERIAL);\n\n-- +goose Down\nDROP TABLE users;\n",
+		"00002_create_index.sql": "-- +goose NO TRANSACTION\n-- +goose Up\nCREATE INDEX CONCURRENTLY users_id_idx ON users (id);\n-- +goose Down\nDROP INDEX users_id_idx;\n",
+		"00003_backfill.go":      "package migrations\n",
+	})
+
+	imported, err := ReadImport(Goose, dir, "postgresql")
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if len(imported.Migrations) != 2 || len(imported.Skipped) != 1 {
+		t.Fatalf(`expected 2 migrations and 1 skipped file, but got %v and %v`, len(imported.Migrations), imported.Skipped)
+	}
+
+	if imported.Migrations[1].SourceVersion != "2" || !imported.Migrations[1].Migration.DisableTransaction {
+		t.Errorf(`expected a different migration, but got %v`, imported.Migrations[1])
+	}
+
+	// Scenario 2: Invalid annotations
+	dir = importFixtures(t, map[string]string{
+		"00001_create_users.sql": "CREATE TABLE users (id SERIAL);\n",
+	})
+
+	_, err = ReadImport(Goose, dir, "postgresql")
+
+	if !errors.As(err, new(ImportError)) {
+		t.Errorf(`expected ImportError, but got %v`, err)
+	}
+}
+
+func TestReadImportFlyway(t *testing.T) {
+	dir := importFixtures(t, map[string]string{
+		"V1__Create_users.sql":  "CREATE TABLE users (id SERIAL);",
+		"V1_1__Add_email.sql":   "ALTER TABLE users ADD COLUMN email VARCHAR;",
+		"U1_1__Add_email.sql":   "ALTER TABLE users DROP COLUMN email;",
+		"V2.0.1__Add_phone.sql": "ALTER TABLE users ADD COLUMN phone VARCHAR;",
+		"R__Users_view.sql":     "CREATE OR REPLACE VIEW active_users AS SELECT * FROM users;",
+	})
+
+	imported, err := ReadImport(Flyway, dir, "postgresql")
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if len(imported.Migrations) != 3 || len(imported.Skipped) != 1 {
+		t.Fatalf(`expected 3 migrations and 1 skipped file, but got %v and %v`, len(imported.Migrations), imported.Skipped)
+	}
+
+	versions := []string{"00000000010000000000", "00000000010000100000", "00000000020000000001"}
+
+	for i, version := range versions {
+		if imported.Migrations[i].Migration.Version != version {
+			t.Errorf(`expected version %v, but got %v`, version, imported.Migrations[i].Migration.Version)
+		}
+	}
+
+	email := imported.Migrations[1]
+
+	if email.SourceVersion != "1.1" || len(email.Files) != 2 || len(email.Migration.Changes.Down) != 1 {
+		t.Errorf(`expected up and undo files to be merged, but got %v`, email)
+	}
+}
+
+func TestReadImportRails(t *testing.T) {
+	dir := importFixtures(t, map[string]string{
+		"20190101120000_create_users.rb": `class CreateUsers < ActiveRecord::Migration[7.0]
+  def up
+    execute <<~SQL
+      CREATE TABLE users (id SERIAL, name VARCHAR NOT NULL);
+      CREATE INDEX users_name_idx ON users (name);
+    SQL
+  end
+
+  def down
+    execute "DROP TABLE users;"
+  end
+end
+`,
+		"20190102120000_create_posts.rb": `class CreatePosts < ActiveRecord::Migration[7.0]
+  def change
+    create_table :posts do |t|
+      t.string :title
+    end
+  end
+end
+`,
+	})
+
+	imported, err := ReadImport(Rails, dir, "postgresql")
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if len(imported.Migrations) != 1 || len(imported.Skipped) != 1 {
+		t.Fatalf(`expected 1 migration and 1 skipped file, but got %v and %v`, len(imported.Migrations), imported.Skipped)
+	}
+
+	users := imported.Migrations[0].Migration
+
+	if len(users.Changes.Up) != 2 || len(users.Changes.Down) != 1 || users.Changes.Down[0] != "DROP TABLE users;" {
+		t.Errorf(`expected a different migration, but got %v`, users.Changes)
+	}
+}
+
+func TestReadImportUnsupportedSource(t *testing.T) {
+	_, err := ReadImport(ImportSource("liquibase"), t.TempDir(), "postgresql")
+
+	if !errors.As(err, new(ImportError)) {
+		t.Errorf(`expected ImportError, but got %v`, err)
+	}
+}
+
+func TestReadImportDuplicateNames(t *testing.T) {
+	dir := importFixtures(t, map[string]string{
+		"1_add_index.up.sql": "CREATE INDEX a_idx ON users (a);",
+		"2_add_index.up.sql": "CREATE INDEX b_idx ON users (b);",
+	})
+
+	_, err := ReadImport(GolangMigrate, dir, "postgresql")
+
+	if !errors.As(err, new(ImportError)) {
+		t.Errorf(`expected ImportError, but got %v`, err)
+	}
+}
+
+func TestImportWrite(t *testing.T) {
+	dir := importFixtures(t, map[string]string{
+		"1_create_users.up.sql":   "CREATE TABLE users (id SERIAL, name VARCHAR NOT NULL);",
+		"1_create_users.down.sql": "DROP TABLE users;",
+		"2_add_email.up.sql":      "ALTER TABLE users ADD COLUMN email VARCHAR;",
+		"2_add_email.down.sql":    "ALTER TABLE users DROP COLUMN email;",
+		"3_add_users_v2.up.sql":   "ALTER TABLE users ADD COLUMN v2 BOOLEAN;",
+		"3_add_users_v2.down.sql": "ALTER TABLE users DROP COLUMN v2;",
+	})
+
+	imported, _ := ReadImport(GolangMigrate, dir, "postgresql")
+	output := t.TempDir()
+
+	written, err := imported.Write(output)
+
+	if err != nil || len(written) != 3 {
+		t.Fatalf(`expected 3 files to be written, but got %v (%v)`, written, err)
+	}
+
+	// NOTE: Names with digits are loaded from the files they are written to
+	list := BuildMigrations(LoadFiles(output, &FilePattern), output, &FilePattern)
+
+	if valid, reason := Validate(list); !valid || list.Size() != 3 {
+		t.Errorf(`expected imported migrations to be valid, but got %v (%v)`, reason, list.Size())
+	}
+
+	// Scenario 2: Existing files are left untouched
+	written, err = imported.Write(output)
+
+	if err != nil || len(written) != 0 {
+		t.Errorf(`expected no files to be written, but got %v (%v)`, written, err)
+	}
+}
+
+func TestImportVersion(t *testing.T) {
+	scenarios := []struct {
+		source  ImportSource
+		version string
+		result  string
+	}{
+		{source: GolangMigrate, version: "1", result: "00000000000000000001"},
+		{source: Goose, version: "00042", result: "00000000000000000042"},
+		{source: Rails, version: "20190101120000", result: "20190101120000000000"},
+		{source: Flyway, version: "1", result: "00000000010000000000"},
+		{source: Flyway, version: "1_2_3", result: "00000000010000200003"},
+		{source: Flyway, version: "1.2.3.4", result: ""},
+		{source: Flyway, version: "1.100000", result: ""},
+		{source: Goose, version: "1.2", result: ""},
+	}
+
+	for _, scenario := range scenarios {
+		version, err := importVersion(scenario.source, scenario.version)
+
+		if version != scenario.result || (scenario.result == "") != (err != nil) {
+			t.Errorf(`expected %v to be imported as %v, but got %v (%v)`, scenario.version, scenario.result, version, err)
+		}
+	}
+}
+
+func TestImportName(t *testing.T) {
+	scenarios := map[string]string{
+		"create_users":     "create_users",
+		"Add_email":        "add_email",
+		"add-users-v2-bio": "add_users_v2_bio",
+		"AddUsersIndex":    "add_users_index",
+		"AddOAuth2Tokens":  "add_oauth2_tokens",
+		"2fa codes":        "2fa_codes",
+		"V2":               "v2",
+		"123":              "imported_migration",
+		"1_2":              "imported_migration",
+		"__trailing__":     "trailing",
+	}
+
+	for name, expected := range scenarios {
+		if importName(name) != expected {
+			t.Errorf(`expected %v to be imported as %v, but got %v`, name, expected, importName(name))
+		}
+	}
+}
+
+func TestRunnerImportHistory(t *testing.T) {
+	store := stores.SQLite3{URL: filepath.Join(t.TempDir(), "test.db")}
+
+	runner := testRunner()
+	runner.SetStore(store)
+
+	dir := importFixtures(t, map[string]string{
+		"00001_create_users.sql":    "-- +goose Up\nCREATE TABLE users (id INTEGER);\n-- +goose Down\nDROP TABLE users;\n",
+		"00002_create_articles.sql": "-- +goose Up\nCREATE TABLE articles (id INTEGER);\n-- +goose Down\nDROP TABLE articles;\n",
+		"00003_create_comments.sql": "-- +goose Up\nCREATE TABLE comments (id INTEGER);\n-- +goose Down\nDROP TABLE comments;\n",
+	})
+
+	store.Create("CREATE TABLE goose_db_version (id INTEGER PRIMARY KEY AUTOINCREMENT, version_id INTEGER NOT NULL, is_applied INTEGER NOT NULL);")
+	store.Create("INSERT INTO goose_db_version (version_id, is_applied) VALUES (0, 1), (1, 1), (2, 1), (3, 1), (3, 0);")
+
+	imported, _ := ReadImport(Goose, dir, "sqlite3")
+
+	recorded, err := runner.ImportHistory(imported, "goose_db_version")
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if len(recorded) != 2 {
+		t.Fatalf(`expected 2 migrations to be recorded, but got %v`, recorded)
+	}
+
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if applied.Size() != 2 || applied.GetTail().Version != "00000000000000000002" {
+		t.Errorf(`expected 2 applied migrations, but got %v`, applied.Description())
+	}
+
+	// Scenario 2: Migrations are recorded only once
+	recorded, err = runner.ImportHistory(imported, "goose_db_version")
+
+	if err != nil || len(recorded) != 0 {
+		t.Errorf(`expected no migrations to be recorded, but got %v (%v)`, recorded, err)
+	}
+
+	// Scenario 3: Missing schema table
+	_, err = runner.ImportHistory(imported, "flyway_schema_history")
+
+	if err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+}
+
+// MARK: - Helpers
+
+func importFixtures(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// DestructiveStatements - Statements that destroy data. Migrations that run them can be refused. See `Migration.Destructive()`.
+	DestructiveStatements = []string{"DROP TABLE", "DROP SCHEMA", "DROP DATABASE", "TRUNCATE"}
+)
+
+// LintRules - Team conventions checked in addition to `Validate()`. The zero value enforces nothing.
+type LintRules struct {
+	// RequireDown - Every migration must be reversible.
+	RequireDown bool `yaml:"require_down,omitempty" json:"require_down,omitempty"`
+
+	// MaxStatements - The maximum number of statements in either direction of a migration. Zero means no limit.
+	MaxStatements int `yaml:"max_statements,omitempty" json:"max_statements,omitempty"`
+
+	// ForbiddenStatements - Statements that migrations may not run when applied (i.e. `TRUNCATE`). Matched case-insensitively.
+	ForbiddenStatements []string `yaml:"forbidden_statements,omitempty" json:"forbidden_statements,omitempty"`
+}
+
+// Lint - Checks a list of migrations against the rules.
+func Lint(migrations MigrationList, rules LintRules) (bool, string) {
+	for migration := migrations.head; migration != nil; migration = migration.next {
+		// NOTE: The changes of Go migrations cannot be inspected
+		if migration.IsGo() {
+			continue
+		}
+
+		if rules.RequireDown && len(migration.Changes.Down) == 0 {
+			return invalidMigration(*migration, "missing rollback instructions")
+		}
+
+		if rules.MaxStatements > 0 && (len(migration.Changes.Up) > rules.MaxStatements || len(migration.Changes.Down) > rules.MaxStatements) {
+			return invalidMigration(*migration, fmt.Sprintf("more than %v statements", rules.MaxStatements))
+		}
+
+		for _, change := range migration.Changes.Up {
+			if forbidden, found := forbiddenStatement(change, rules.ForbiddenStatements); found {
+				return invalidMigration(*migration, fmt.Sprintf("forbidden statement '%v'", forbidden))
+			}
+		}
+	}
+
+	return true, ""
+}
+
+// Destructive - Returns the statements that destroy data when the migration is applied. See `DestructiveStatements`.
+func (M Migration) Destructive() []string {
+	destructive := []string{}
+
+	for _, change := range M.Changes.Up {
+		if statement, found := forbiddenStatement(change, DestructiveStatements); found {
+			destructive = append(destructive, statement)
+		}
+	}
+
+	return destructive
+}
+
+func forbiddenStatement(change string, forbidden []string) (string, bool) {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(change), " "))
+
+	for _, statement := range forbidden {
+		if strings.Contains(normalized, strings.ToUpper(strings.Join(strings.Fields(statement), " "))) {
+			return statement, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	irreversible := Migration{
+		Version:  "20221231054540000000",
+		Engine:   "postgresql",
+		Name:     "TruncateLikes",
+		FileName: "20221231054540000000_truncate_likes.yaml",
+		Changes:  Changes{Up: []string{"truncate   likes;"}},
+	}
+
+	scenarios := []struct {
+		rules  LintRules
+		valid  bool
+		reason string
+	}{
+		{LintRules{}, true, ""},
+		{LintRules{RequireDown: true}, false, "missing rollback instructions"},
+		{LintRules{MaxStatements: 1}, true, ""},
+		{LintRules{ForbiddenStatements: []string{"DROP TABLE", "TRUNCATE"}}, false, "forbidden statement 'TRUNCATE'"},
+	}
+
+	for _, scenario := range scenarios {
+		list := defaultMigrationList()
+		migration := irreversible
+		list.Insert(&migration)
+
+		valid, reason := Lint(list, scenario.rules)
+
+		if valid != scenario.valid || !strings.Contains(reason, scenario.reason) {
+			t.Errorf(`expected valid == %v (%v), but got %v (%v)`, scenario.valid, scenario.reason, valid, reason)
+		}
+	}
+}
+
+func TestMigrationDestructive(t *testing.T) {
+	migration := Migration{
+		Changes: Changes{
+			Up:   []string{"CREATE TABLE likes (id SERIAL);", "drop  table users;"},
+			Down: []string{"DROP TABLE likes;"},
+		},
+	}
+
+	// NOTE: Rollbacks are not considered
+	if destructive := migration.Destructive(); len(destructive) != 1 || destructive[0] != "DROP TABLE" {
+		t.Errorf(`expected a destructive statement, but got %v`, destructive)
+	}
+}
@@ -0,0 +1,126 @@
+package migrations
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+)
+
+// DefaultLockTimeout - How long the runner waits for another runner to release the migration lock.
+const DefaultLockTimeout = time.Minute
+
+// lockKey - Identifies the advisory lock guarding a schema table.
+func lockKey(schemaTable string) int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(fmt.Sprintf("dm:%v", schemaTable)))
+
+	return int64(hash.Sum64())
+}
+
+// acquireLock - Prevents other runners from migrating the same database until the returned function is called.
+// Stores that implement `Locker` use a native lock. All other stores use a lock table.
+func (runner *Runner) acquireLock() (func() error, error) {
+	timeout := runner.lockTimeout
+
+	if timeout == 0 {
+		timeout = DefaultLockTimeout
+	}
+
+	if locker, ok := runner.store.(Locker); ok {
+		return locker.Lock(lockKey(runner.schemaTable), timeout)
+	}
+
+	err := runner.store.Create(CreateLockTable(runner.schemaTable))
+
+	if err != nil {
+		return nil, err
+	}
+
+	execution := runner.execution()
+
+	release := func() error {
+		return runner.store.Delete(DeleteLockEntry(runner.schemaTable))
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		// NOTE: The insertion fails while another runner holds the lock
+		err = runner.store.Create(CreateLockEntry(DialectOf(runner.store), runner.schemaTable), execution.AppliedBy, execution.Host, os.Getpid())
+
+		if err == nil {
+			return release, nil
+		}
+
+		if time.Now().After(deadline) {
+			holder, _ := runner.LockHolder()
+
+			if holder == nil {
+				return nil, fmt.Errorf("timed out after %v waiting for %v. %v", timeout, LockTable(runner.schemaTable), err)
+			}
+
+			return nil, &LockError{Holder: holder}
+		}
+
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// LockHolder - Describes the runner holding a lock table entry.
+type LockHolder struct {
+	Owner      string    `json:"owner" db:"owner"`
+	Host       string    `json:"host" db:"host"`
+	PID        int       `json:"pid" db:"pid"`
+	AcquiredAt time.Time `json:"acquired_at" db:"acquired_at"`
+}
+
+// Description - Who holds the lock, and since when.
+func (H LockHolder) Description() string {
+	return fmt.Sprintf("%v@%v (pid %v) since %v", H.Owner, H.Host, H.PID, H.AcquiredAt.Format(time.RFC3339))
+}
+
+// LockHolder - The runner holding the lock table entry, or `nil` when the lock is not held.
+// Native locks are not recorded, so their holder is never known.
+func (runner *Runner) LockHolder() (*LockHolder, error) {
+	if _, ok := runner.store.(Locker); ok {
+		return nil, nil
+	}
+
+	var holders []LockHolder
+
+	err := runner.store.Read(SelectLockEntry(runner.schemaTable), &holders)
+
+	if err != nil || len(holders) == 0 {
+		return nil, err
+	}
+
+	return &holders[0], nil
+}
+
+// ReleaseLock - Forcefully releases a lock left behind by a runner that did not exit cleanly.
+// Native locks are released by the database when their session ends, so this only applies to lock tables.
+func (runner *Runner) ReleaseLock() error {
+	runner.beforeAction()
+
+	if _, ok := runner.store.(Locker); ok {
+		runner.LogInfo(fmt.Sprintf("%v locks are released automatically.", runner.store.Name()))
+		return nil
+	}
+
+	holder, _ := runner.LockHolder()
+
+	err := runner.store.Delete(DeleteLockEntry(runner.schemaTable))
+
+	if err != nil && IsTracked(runner.store, LockTable(runner.schemaTable)) {
+		return err
+	}
+
+	if holder == nil {
+		runner.LogInfo("Migration lock released.")
+		return nil
+	}
+
+	runner.LogInfo(fmt.Sprintf("Migration lock held by %v released.", holder.Description()))
+	return nil
+}
@@ -1,13 +1,17 @@
 package migrations
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
-	"io/ioutil"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
 
+	"github.com/iancoleman/strcase"
 	"gopkg.in/yaml.v2"
 )
 
@@ -28,6 +32,43 @@ type Migration struct {
 	Name     string  `yaml:"name"`
 	Engine   string  `yaml:"engine" json:"-"`
 	Changes  Changes `yaml:"changes,omitempty" json:"-"`
+
+	// Path - The path of the file the migration was loaded from, relative to the working directory.
+	// Migrations can be organized in subdirectories or spread across several directories. See `LoadMigrations()`.
+	Path string `yaml:"-" json:"path,omitempty"`
+
+	// UpFunc, DownFunc - The changes of Go migrations, used instead of `Changes`. See `Register()`.
+	UpFunc   MigrationFunc `yaml:"-" json:"-"`
+	DownFunc MigrationFunc `yaml:"-" json:"-"`
+
+	// DisableTransaction - Opts out of running the migration inside a transaction.
+	// Required for statements such as `CREATE INDEX CONCURRENTLY`.
+	DisableTransaction bool `yaml:"disable_transaction,omitempty" json:"-"`
+
+	// Checksum - The checksum of the changes recorded when the migration was applied.
+	Checksum string `yaml:"-" json:"checksum,omitempty"`
+
+	// Drifted - Indicates that the file of an applied migration no longer matches its recorded checksum.
+	Drifted bool `yaml:"-" json:"drifted,omitempty"`
+
+	// Baselined - Indicates that the migration was recorded as applied without being run. See `Runner.Baseline()`.
+	Baselined bool `yaml:"-" json:"baselined,omitempty"`
+
+	// Dirty - Indicates that the migration failed part-way without a transaction to undo its statements.
+	// The runner refuses to run while a dirty migration exists. See `Runner.Repair()`.
+	Dirty bool `yaml:"-" json:"dirty,omitempty"`
+
+	// FailedStatement - The index of the statement that left the migration dirty, if known.
+	FailedStatement *int `yaml:"-" json:"failed_statement,omitempty" db:"failed_statement"`
+
+	// Batch - The run of `Runner.Up()` that applied the migration. Migrations applied in the same run share a batch.
+	Batch int `yaml:"-" json:"batch,omitempty"`
+
+	// AppliedAt - When an applied migration was recorded in the schema table.
+	AppliedAt *time.Time `yaml:"-" json:"applied_at,omitempty" db:"created_at"`
+
+	Execution `yaml:"-"`
+
 	next     *Migration
 	previous *Migration
 }
@@ -42,6 +83,16 @@ type MigratorVersion struct {
 	Name      string    `json:"name"`
 	Version   string    `json:"version"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	Execution
+}
+
+type TableColumn struct {
+	Name       string
+	Definition string
+
+	// Default - Read instead of the column while the schema table lacks it (i.e. before it is upgraded).
+	Default string
 }
 
 type TableSchema struct {
@@ -55,7 +106,57 @@ func (V *MigratorVersion) Description() string {
 }
 
 func (M Migration) Description() string {
-	return fmt.Sprintf("Version: %v (%v)", M.Version, M.Name)
+	description := fmt.Sprintf("Version: %v (%v)", M.Version, M.Name)
+
+	if M.IsGo() {
+		description += " [go]"
+	}
+
+	if M.Batch > 0 {
+		description += fmt.Sprintf(" [batch %v]", M.Batch)
+	}
+
+	if M.Baselined {
+		description += " [baselined]"
+	}
+
+	if M.Drifted {
+		description += " [drifted]"
+	}
+
+	if M.Dirty && M.FailedStatement != nil {
+		description += fmt.Sprintf(" [dirty: statement %v failed]", *M.FailedStatement)
+	} else if M.Dirty {
+		description += " [dirty]"
+	}
+
+	if M.Path != "" {
+		description += fmt.Sprintf(" from %v", M.Path)
+	}
+
+	if summary := M.Summary(); summary != "" {
+		description += fmt.Sprintf(" - %v", summary)
+	}
+
+	return description
+}
+
+// Checksum - Returns a SHA-256 checksum of the changes.
+// Statements are normalized beforehand, so changes in whitespace or indentation do not affect the result, unless they are made inside strings.
+func (C Changes) Checksum() string {
+	normalized := func(statements []string) string {
+		result := []string{}
+
+		for _, statement := range statements {
+			result = append(result, normalizeStatement(statement))
+		}
+
+		return strings.Join(result, "\n")
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("up:\n%v\ndown:\n%v", normalized(C.Up), normalized(C.Down))))
+
+	return hex.EncodeToString(sum[:])
 }
 
 // MARK: - Implements LinkedList behavior
@@ -106,30 +207,54 @@ func (m Migrations) ToHash() map[string]Migration {
 	return hash
 }
 
+// Versions - The versions of the migrations, in order.
+func (m Migrations) Versions() []string {
+	versions := []string{}
+
+	for _, migration := range m {
+		versions = append(versions, migration.Version)
+	}
+
+	return versions
+}
+
 // MARK: - Migration loader
 
 func (instance *Migration) Load(file fs.FileInfo, parent string, pattern *regexp.Regexp) error {
-	path := filepath.Join(parent, file.Name())
-
-	path, _ = filepath.Abs(path)
+	return instance.LoadFS(DirFS(parent), file, pattern)
+}
 
-	contents, err := ioutil.ReadFile(path)
+// LoadFS - Loads the migration from a file in the file system.
+func (instance *Migration) LoadFS(fsys fs.FS, file fs.FileInfo, pattern *regexp.Regexp) error {
+	contents, err := fs.ReadFile(fsys, file.Name())
 
 	if err != nil {
 		return err
 	}
 
-	err = yaml.Unmarshal(contents, &instance)
+	switch filepath.Ext(file.Name()) {
+	case ".sql":
+		err = instance.loadSQL(contents)
+	default:
+		err = yaml.Unmarshal(contents, &instance)
+	}
 
 	if err != nil {
 		return err
 	}
 
-	match := pattern.FindStringSubmatch(file.Name())
+	// NOTE: Files found in subdirectories are named after their path
+	name := path.Base(file.Name())
+	match := pattern.FindStringSubmatch(name)
 
-	instance.FileName = file.Name()
+	instance.FileName = name
 	instance.Version = match[pattern.SubexpIndex("Version")]
 
+	// NOTE: SQL files do not declare a name
+	if instance.Name == "" {
+		instance.Name = strcase.ToCamel(match[pattern.SubexpIndex("Name")])
+	}
+
 	return nil
 }
 
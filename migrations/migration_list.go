@@ -105,13 +105,17 @@ func (List *MigrationList) Find(identifier string) (sequence MigrationList, isFo
 
 	for curr != nil {
 		sequence.Insert(&Migration{
-			Changes:  curr.Changes,
-			Engine:   curr.Engine,
-			FileName: curr.FileName,
-			Id:       curr.Id,
-			Name:     curr.Name,
-			Schema:   curr.Schema,
-			Version:  curr.Version,
+			Changes:            curr.Changes,
+			Engine:             curr.Engine,
+			FileName:           curr.FileName,
+			Path:               curr.Path,
+			Id:                 curr.Id,
+			Name:               curr.Name,
+			Schema:             curr.Schema,
+			Version:            curr.Version,
+			DisableTransaction: curr.DisableTransaction,
+			UpFunc:             curr.UpFunc,
+			DownFunc:           curr.DownFunc,
 		})
 
 		if curr.Version == identifier || curr.Name == identifier {
@@ -124,6 +128,42 @@ func (List *MigrationList) Find(identifier string) (sequence MigrationList, isFo
 	return MigrationList{}, false
 }
 
+// Take - Returns the first n migrations in the list.
+func (List *MigrationList) Take(n int) (sequence MigrationList) {
+	curr := List.head
+
+	for curr != nil && sequence.size < n {
+		migration := *curr
+		migration.next = nil
+		migration.previous = nil
+
+		sequence.Insert(&migration)
+
+		curr = curr.next
+	}
+
+	return sequence
+}
+
+// SinceBatch - Returns the migrations applied in the given batch or in any batch after it.
+func (List *MigrationList) SinceBatch(batch int) (sequence MigrationList) {
+	curr := List.head
+
+	for curr != nil {
+		if curr.Batch >= batch {
+			migration := *curr
+			migration.next = nil
+			migration.previous = nil
+
+			sequence.Insert(&migration)
+		}
+
+		curr = curr.next
+	}
+
+	return sequence
+}
+
 // FromMap - Inserts map elements into the list.
 func (List *MigrationList) FromMap(m map[string]Migration) {
 	for _, value := range m {
@@ -257,6 +257,60 @@ func TestFindByName(t *testing.T) {
 	}
 }
 
+func TestTake(t *testing.T) {
+	list := defaultList()
+
+	// Scenario 1: Fewer migrations than the list holds
+	sequence := list.Take(1)
+
+	if sequence.size != 1 || sequence.GetHead().Version != list.GetHead().Version || sequence.GetHead().Next() != nil {
+		t.Fatalf(`wanted sequence.size == 1, but got %v`, sequence.size)
+	}
+
+	// Scenario 2: More migrations than the list holds
+	sequence = list.Take(list.size + 1)
+
+	if sequence.size != list.size {
+		t.Errorf(`wanted sequence.size == %v, but got %v`, list.size, sequence.size)
+	}
+
+	// Scenario 3: No migrations
+	sequence = list.Take(0)
+
+	if sequence.size != 0 {
+		t.Errorf(`wanted sequence.size == 0, but got %v`, sequence.size)
+	}
+}
+
+func TestSinceBatch(t *testing.T) {
+	list := defaultList()
+	batch := 1
+
+	for curr := list.GetHead(); curr != nil; curr = curr.Next() {
+		curr.Batch = batch
+		batch++
+	}
+
+	// Scenario 1: Migrations in the batch and in later batches
+	sequence := list.SinceBatch(2)
+
+	if sequence.size != list.size-1 || sequence.GetHead().Batch != 2 {
+		t.Fatalf(`wanted sequence.size == %v, but got %v`, list.size-1, sequence.size)
+	}
+
+	// NOTE: The original list is left untouched
+	if list.GetTail().Next() != nil || list.GetHead().Batch != 1 {
+		t.Errorf(`expected the original list to be unchanged`)
+	}
+
+	// Scenario 2: No migrations in the batch
+	sequence = list.SinceBatch(batch)
+
+	if sequence.size != 0 {
+		t.Errorf(`wanted sequence.size == 0, but got %v`, sequence.size)
+	}
+}
+
 func TestFromMap(t *testing.T) {
 	hash := defaultMap()
 	list := MigrationList{}
@@ -223,3 +223,70 @@ func testMigrationFile() MigrationFile {
 		size:    42,
 	}
 }
+
+// MARK: - Checksum
+
+func TestChangesChecksum(t *testing.T) {
+	changes := Changes{
+		Up:   []string{"CREATE TABLE likes (id SERIAL, content_id INT NOT NULL);"},
+		Down: []string{"DROP TABLE likes;"},
+	}
+
+	// Scenario 1: Whitespace does not affect the checksum
+	reformatted := Changes{
+		Up:   []string{"CREATE TABLE likes (id SERIAL,\n  content_id INT NOT NULL);\n"},
+		Down: []string{"  DROP TABLE   likes;"},
+	}
+
+	if changes.Checksum() != reformatted.Checksum() {
+		t.Errorf(`expected checksums to match, but got %v and %v`, changes.Checksum(), reformatted.Checksum())
+	}
+
+	// Scenario 2: Modified statements change the checksum
+	modified := Changes{
+		Up:   []string{"CREATE TABLE likes (id SERIAL, content_id BIGINT NOT NULL);"},
+		Down: []string{"DROP TABLE likes;"},
+	}
+
+	if changes.Checksum() == modified.Checksum() {
+		t.Errorf(`expected checksums to differ, but got %v`, modified.Checksum())
+	}
+
+	// Scenario 3: Moving statements between up and down changes the checksum
+	swapped := Changes{Up: changes.Down, Down: changes.Up}
+
+	if changes.Checksum() == swapped.Checksum() {
+		t.Errorf(`expected checksums to differ, but got %v`, swapped.Checksum())
+	}
+
+	// Scenario 4: Whitespace inside strings changes the checksum
+	literal := Changes{Up: []string{"INSERT INTO notes (body) VALUES ('a  b');"}}
+	edited := Changes{Up: []string{"INSERT  INTO notes (body)\r\n  VALUES ('a b');"}}
+
+	if literal.Checksum() == edited.Checksum() {
+		t.Errorf(`expected checksums to differ, but got %v`, edited.Checksum())
+	}
+
+	// NOTE: Whitespace outside of strings, comments, and dollar-quoted bodies is normalized
+	edited = Changes{Up: []string{"INSERT  INTO notes (body)\r\n  VALUES ('a  b');\n"}}
+
+	if literal.Checksum() != edited.Checksum() {
+		t.Errorf(`expected checksums to match, but got %v and %v`, literal.Checksum(), edited.Checksum())
+	}
+}
+
+func TestMigrationDescriptionWhenDrifted(t *testing.T) {
+	migration := Migration{Version: "20221231054540", Name: "CreateLikes", Drifted: true}
+
+	if migration.Description() != "Version: 20221231054540 (CreateLikes) [drifted]" {
+		t.Errorf(`expected a different description, got %v`, migration.Description())
+	}
+}
+
+func TestMigrationDescriptionWhenBaselined(t *testing.T) {
+	migration := Migration{Version: "20221231054540", Name: "CreateLikes", Baselined: true}
+
+	if migration.Description() != "Version: 20221231054540 (CreateLikes) [baselined]" {
+		t.Errorf(`expected a different description, got %v`, migration.Description())
+	}
+}
@@ -0,0 +1,205 @@
+package migrations
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Plan - Describes the changes a runner would make to the database, without making them.
+type Plan struct {
+	Direction  string             `json:"direction" yaml:"direction"`
+	Setup      []Statement        `json:"setup,omitempty" yaml:"setup,omitempty"`
+	Migrations []PlannedMigration `json:"migrations" yaml:"migrations"`
+}
+
+type PlannedMigration struct {
+	Version     string      `json:"version" yaml:"version"`
+	Name        string      `json:"name" yaml:"name"`
+	Transaction bool        `json:"transaction" yaml:"transaction"`
+	Statements  []Statement `json:"statements" yaml:"statements"`
+
+	// Go - The changes of Go migrations are not planned, only their tracking. See `Migration.IsGo()`.
+	Go bool `json:"go,omitempty" yaml:"go,omitempty"`
+}
+
+type Statement struct {
+	Query string        `json:"query" yaml:"query"`
+	Args  []interface{} `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// MARK: - Implements Formattable
+
+func (P Plan) Description() string {
+	if len(P.Setup) == 0 && len(P.Migrations) == 0 {
+		return "Nothing to do."
+	}
+
+	descriptions := []string{fmt.Sprintf("Plan (%v): %v migration(s)", P.Direction, len(P.Migrations))}
+
+	if len(P.Setup) != 0 {
+		descriptions = append(descriptions, "\n-- Setup")
+
+		for _, statement := range P.Setup {
+			descriptions = append(descriptions, statement.Description())
+		}
+	}
+
+	for _, migration := range P.Migrations {
+		descriptions = append(descriptions, fmt.Sprintf("\n-- %v", migration.Description()))
+
+		for _, statement := range migration.Statements {
+			descriptions = append(descriptions, statement.Description())
+		}
+	}
+
+	return strings.Join(descriptions, "\n")
+}
+
+func (P PlannedMigration) Description() string {
+	description := fmt.Sprintf("Version: %v (%v)", P.Version, P.Name)
+
+	if !P.Transaction {
+		description += " [no transaction]"
+	}
+
+	if P.Go {
+		description += " [Go migration, not planned]"
+	}
+
+	return description
+}
+
+func (S Statement) Description() string {
+	query := strings.TrimSpace(S.Query)
+
+	if len(S.Args) == 0 {
+		return query
+	}
+
+	args := []string{}
+
+	for _, arg := range S.Args {
+		args = append(args, fmt.Sprintf("%v", arg))
+	}
+
+	return fmt.Sprintf("%v -- %v", query, strings.Join(args, ", "))
+}
+
+// MARK: - Planner
+
+// PlanUp - Returns the statements `Up()` would execute for the given migrations.
+func (runner *Runner) PlanUp(migrations MigrationList) (Plan, error) {
+	return runner.plan("up", migrations, (*Runner).applyMigration)
+}
+
+// PlanDown - Returns the statements `Down()` would execute for the given migrations.
+func (runner *Runner) PlanDown(migrations MigrationList) (Plan, error) {
+	return runner.plan("down", migrations, (*Runner).revertMigration)
+}
+
+func (runner *Runner) plan(direction string, migrations MigrationList, action func(*Runner, Migration) error) (Plan, error) {
+	runner.beforeAction()
+
+	plan := Plan{Direction: direction, Migrations: []PlannedMigration{}}
+
+	valid, reason := Validate(migrations)
+
+	if !valid {
+		runner.LogError(reason)
+		return plan, new(ValidationError)
+	}
+
+	if err := runner.refuseDirty(); err != nil {
+		return plan, err
+	}
+
+	// NOTE: Writes are recorded instead of executed. Reads still reach the database.
+	setup := &recorder{store: runner.store}
+
+	if !IsTracked(runner.store, runner.schemaTable) {
+		if direction == "down" {
+			return plan, nil
+		}
+
+		StartTracking(setup, runner.schemaTable)
+	} else {
+		UpgradeTracking(setup, runner.schemaTable)
+
+		// NOTE: Accepted drift is planned along with the setup
+		if direction == "up" {
+			if err := runner.refuseDrift(setup, migrations); err != nil {
+				return plan, err
+			}
+		}
+
+		migrations = runner.exclude(migrations, direction == "up")
+	}
+
+	plan.Setup = setup.statements
+
+	if direction == "up" {
+		runner.batch = runner.LastBatch() + 1
+	}
+
+	migration := migrations.GetHead()
+
+	for migration != nil {
+		planner := *runner
+		store := &recorder{store: runner.store}
+		planner.store = store
+		planner.events = nil
+		planner.planning = true
+
+		err := action(&planner, *migration)
+
+		if err != nil {
+			return plan, err
+		}
+
+		plan.Migrations = append(plan.Migrations, PlannedMigration{
+			Version:     migration.Version,
+			Name:        migration.Name,
+			Transaction: !migration.DisableTransaction,
+			Statements:  store.statements,
+			Go:          migration.IsGo(),
+		})
+
+		migration = migration.Next()
+	}
+
+	return plan, nil
+}
+
+// MARK: - Recorder (implements the Store interface)
+
+// recorder - A store that records the statements that would modify the database instead of executing them.
+type recorder struct {
+	store      Store
+	statements []Statement
+}
+
+func (r *recorder) Create(query string, options ...interface{}) error {
+	r.statements = append(r.statements, Statement{Query: query, Args: options})
+	return nil
+}
+
+func (r *recorder) Read(query string, model interface{}, options ...interface{}) error {
+	return r.store.Read(query, model, options...)
+}
+
+func (r *recorder) Delete(query string, options ...interface{}) error {
+	r.statements = append(r.statements, Statement{Query: query, Args: options})
+	return nil
+}
+
+func (r *recorder) Transaction(fn func(Store) error) error {
+	return fn(r)
+}
+
+func (r *recorder) Name() string {
+	return r.store.Name()
+}
+
+func (r *recorder) DatabaseURL() string {
+	return r.store.DatabaseURL()
+}
@@ -0,0 +1,110 @@
+package migrations
+
+import (
+	"testing"
+)
+
+func TestPlanDescription(t *testing.T) {
+	// Scenario 1: An empty plan
+	plan := Plan{Direction: "up"}
+
+	if plan.Description() != "Nothing to do." {
+		t.Errorf(`expected a different description, got %v`, plan.Description())
+	}
+
+	// Scenario 2: A plan with migrations
+	plan.Migrations = []PlannedMigration{
+		{
+			Version:     "20221231054540",
+			Name:        "CreateLikes",
+			Transaction: false,
+			Statements: []Statement{
+				{Query: "CREATE TABLE likes (id SERIAL);\n"},
+				{Query: "INSERT INTO schema_migrations (version, name) VALUES ($1, $2);", Args: []interface{}{"20221231054540", "CreateLikes"}},
+			},
+		},
+	}
+
+	description := `Plan (up): 1 migration(s)
+
+-- Version: 20221231054540 (CreateLikes) [no transaction]
+CREATE TABLE likes (id SERIAL);
+INSERT INTO schema_migrations (version, name) VALUES ($1, $2); -- 20221231054540, CreateLikes`
+
+	if plan.Description() != description {
+		t.Errorf(`expected a different description, got %v`, plan.Description())
+	}
+}
+
+func TestRunnerPlanUp(t *testing.T) {
+	runner := testRunner()
+	runner.SetDryRun(true)
+
+	// Scenario 1: An untracked database
+	plan, err := runner.PlanUp(defaultMigrationList())
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	if len(plan.Setup) != 2 || len(plan.Migrations) != 3 {
+		t.Errorf(`expected 2 setup statements and 3 migrations, but got %v and %v`, len(plan.Setup), len(plan.Migrations))
+	}
+
+	// NOTE: The change, its entry in the schema table, and its entry in the history table
+	for _, migration := range plan.Migrations {
+		if len(migration.Statements) != 3 {
+			t.Errorf(`expected 3 statements, but got %v`, len(migration.Statements))
+		}
+	}
+
+	// Scenario 2: The database was not modified
+	if IsTracked(testPostgresStore, runner.schemaTable) || IsTracked(testPostgresStore, "users") {
+		t.Errorf(`expected the database not to have been modified`)
+	}
+
+	// Scenario 3: Applied migrations are excluded
+	runner.SetDryRun(false)
+	runner.Up(defaultMigrationList())
+
+	plan, err = runner.PlanUp(defaultMigrationList())
+
+	if err != nil || len(plan.Setup) != 0 || len(plan.Migrations) != 0 {
+		t.Errorf(`expected an empty plan, but got %v (%v)`, plan, err)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerPlanDown(t *testing.T) {
+	runner := testRunner()
+
+	// Scenario 1: An untracked database
+	plan, err := runner.PlanDown(defaultMigrationList())
+
+	if err != nil || len(plan.Migrations) != 0 {
+		t.Errorf(`expected an empty plan, but got %v (%v)`, plan, err)
+	}
+
+	// Scenario 2: Applied migrations are reverted
+	runner.Up(defaultMigrationList())
+
+	list := defaultMigrationList()
+	list.Reverse()
+
+	plan, err = runner.PlanDown(list)
+
+	if err != nil || len(plan.Migrations) != 3 {
+		t.Fatalf(`expected 3 migrations, but got %v (%v)`, len(plan.Migrations), err)
+	}
+
+	if plan.Migrations[0].Version != list.GetHead().Version {
+		t.Errorf(`expected %v to be reverted first, but got %v`, list.GetHead().Version, plan.Migrations[0].Version)
+	}
+
+	if !IsTracked(testPostgresStore, "comments") {
+		t.Errorf(`expected the database not to have been modified`)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
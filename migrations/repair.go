@@ -0,0 +1,164 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+Dirty migrations:
+	A migration that fails part-way without a transaction to undo its statements (see `Migration.DisableTransaction`)
+	leaves the database somewhere between its before and after states. Such migrations are kept in the schema table
+	flagged as dirty, along with the index of the statement that failed. Migrations and rollbacks are refused until
+	an operator fixes the database by hand and repairs the entry. See `Runner.Repair()`.
+*/
+
+// DirtyMigrations - Returns the migrations that were left dirty by a failed migration or rollback.
+func (runner *Runner) DirtyMigrations() Migrations {
+	migrated := Migrations{}
+	dirty := Migrations{}
+
+	if !IsTracked(runner.store, runner.schemaTable) {
+		return dirty
+	}
+
+	err := readTracked(runner.store, runner.schemaTable, SelectMigrations, &migrated)
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("An error occurred.\nError: %v\n", err))
+		return dirty
+	}
+
+	for _, migration := range migrated {
+		if migration.Dirty {
+			dirty = append(dirty, migration)
+		}
+	}
+
+	return dirty
+}
+
+// Repair - Clears the dirty state of a migration once the database was fixed by hand.
+// The target (a name or version) is either kept as applied or removed from the schema table (applied == false).
+// When the file of the migration is among the provided migrations, its current checksum is recorded.
+func (runner *Runner) Repair(target string, migrations MigrationList, applied bool) error {
+	runner.beforeAction()
+
+	release, err := runner.lock()
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	var migration *Migration
+
+	for _, dirty := range runner.DirtyMigrations() {
+		if dirty.Version == target || dirty.Name == target {
+			migration = &dirty
+			break
+		}
+	}
+
+	if migration == nil {
+		runner.LogError(fmt.Sprintf("Migration '%v' is not dirty.", target))
+		return new(ValidationError)
+	}
+
+	if file, found := migrations.ToMap()[migration.Version]; found {
+		migration.Checksum = file.Changes.Checksum()
+	}
+
+	err = runner.store.Transaction(func(store Store) error {
+		var err error
+
+		if applied {
+			err = store.Create(MarkMigrationClean(DialectOf(store), runner.schemaTable), migration.Checksum, migration.Version)
+		} else {
+			err = runner.removeMigrationFromSchema(store, *migration, runner.schemaTable)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return runner.recordHistory(store, *migration, HistoryRepair, nil)
+	})
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("\nRepair of '%v' (%v) failed.\n%v \n", migration.Name, migration.Version, err))
+		return err
+	}
+
+	state := "applied"
+
+	if !applied {
+		state = "reverted"
+	}
+
+	runner.LogInfo(fmt.Sprintf("Version: %v (%v) repaired as %v", migration.Version, migration.Name, state))
+
+	return nil
+}
+
+// refuseDirty - Returns an error if a previous run left dirty migrations behind.
+func (runner *Runner) refuseDirty() error {
+	dirty := runner.DirtyMigrations()
+
+	if dirty.Len() == 0 {
+		return nil
+	}
+
+	runner.LogError(fmt.Sprintf(
+		"A previous run left these migrations dirty:\n%vFix the database by hand, then run `dm repair NAME|VERSION --applied` or `dm repair NAME|VERSION --reverted`.",
+		dirty.Description(),
+	))
+
+	return new(DirtyError)
+}
+
+// isAtomic - Whether a failure of the migration is guaranteed to leave no changes behind.
+// MySQL implicitly commits most DDL statements, so its migrations are never considered atomic.
+func (runner *Runner) isAtomic(migration Migration) bool {
+	return !migration.DisableTransaction && DialectOf(runner.store) != MySQLDialect
+}
+
+// markDirty - Records that the migration failed part-way, along with the index of the failed statement.
+func (runner *Runner) markDirty(migration Migration, failure error, applying bool) {
+	var failed interface{}
+	var statement StatementError
+
+	if errors.As(failure, &statement) {
+		failed = statement.Index
+	}
+
+	var err error
+
+	if applying {
+		execution := runner.execution()
+
+		err = runner.store.Create(
+			CreateDirtyEntry(DialectOf(runner.store), runner.schemaTable),
+			migration.Version,
+			migration.Name,
+			migration.Changes.Checksum(),
+			migration.DurationMs,
+			execution.AppliedBy,
+			execution.Host,
+			execution.ToolVersion,
+			execution.Source,
+			runner.batch,
+			failed,
+		)
+	}
+
+	// NOTE: Rollbacks (and migrations whose entry was registered before failing) are already in the schema table
+	if !applying || err != nil {
+		err = runner.store.Create(MarkMigrationDirty(DialectOf(runner.store), runner.schemaTable), failed, migration.Version)
+	}
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("Unable to mark '%v' (%v) as dirty.\nError: %v\n", migration.Name, migration.Version, err))
+	}
+}
@@ -0,0 +1,155 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+)
+
+func partialMigration() Migration {
+	return Migration{
+		Version:            "20221231054540000000",
+		Engine:             "postgresql",
+		Name:               "CreateLikes",
+		FileName:           "20221231054540000000_create_likes.yaml",
+		DisableTransaction: true,
+		Changes: Changes{
+			Up:   []string{"CREATE TABLE likes (id SERIAL);", "INSERT INTO missing_table VALUES (1);"},
+			Down: []string{"DROP TABLE likes;"},
+		},
+	}
+}
+
+func TestMigrationDescriptionWhenDirty(t *testing.T) {
+	index := 1
+	migration := Migration{Version: "20221231054540000000", Name: "CreateLikes", Dirty: true, FailedStatement: &index}
+
+	if migration.Description() != "Version: 20221231054540000000 (CreateLikes) [dirty: statement 1 failed]" {
+		t.Errorf(`expected a different description, got %v`, migration.Description())
+	}
+}
+
+func TestRunnerMarksPartialMigrationsDirty(t *testing.T) {
+	runner := testRunner()
+	migration := partialMigration()
+
+	var list MigrationList
+	list.Insert(&migration)
+
+	// Scenario 1: The failed statement is recorded
+	err := runner.Up(list)
+
+	if !errors.As(err, new(StatementError)) {
+		t.Fatalf(`expected a statement error, but got %v`, err)
+	}
+
+	dirty := runner.DirtyMigrations()
+
+	if dirty.Len() != 1 || dirty[0].FailedStatement == nil || *dirty[0].FailedStatement != 1 {
+		t.Fatalf(`expected a dirty migration, but got %v`, dirty.Description())
+	}
+
+	// Scenario 2: Migrations and rollbacks are refused
+	if err = runner.Up(defaultMigrationList()); !errors.As(err, new(*DirtyError)) {
+		t.Errorf(`expected a dirty error, but got %v`, err)
+	}
+
+	if err = runner.Down(list); !errors.As(err, new(*DirtyError)) {
+		t.Errorf(`expected a dirty error, but got %v`, err)
+	}
+
+	if err = runner.Check(defaultMigrationList()); !errors.As(err, new(*DirtyError)) {
+		t.Errorf(`expected a dirty error, but got %v`, err)
+	}
+
+	if IsTracked(runner.store, "users") {
+		t.Errorf(`expected migrations not to have been run`)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerDoesNotMarkTransactionalMigrationsDirty(t *testing.T) {
+	runner := testRunner()
+	migration := partialMigration()
+	migration.DisableTransaction = false
+
+	var list MigrationList
+	list.Insert(&migration)
+
+	runner.Up(list)
+
+	if dirty := runner.DirtyMigrations(); dirty.Len() != 0 {
+		t.Errorf(`expected no dirty migrations, but got %v`, dirty.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerMarksPartialRollbacksDirty(t *testing.T) {
+	runner := testRunner()
+	migration := *defaultMigrationList().head
+
+	StartTracking(runner.store, runner.schemaTable)
+	runner.applyMigration(migration)
+
+	failing := migration
+	failing.DisableTransaction = true
+	failing.Changes.Down = []string{"DROP TABLE users;", "DROP TABLE users;"}
+
+	runner.revertMigration(failing)
+
+	dirty := runner.DirtyMigrations()
+
+	if dirty.Len() != 1 || dirty[0].Version != migration.Version || *dirty[0].FailedStatement != 1 {
+		t.Errorf(`expected a dirty migration, but got %v`, dirty.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerRepair(t *testing.T) {
+	runner := testRunner()
+	migration := partialMigration()
+
+	var list MigrationList
+	list.Insert(&migration)
+
+	// Scenario 1: Only dirty migrations can be repaired
+	StartTracking(runner.store, runner.schemaTable)
+
+	if err := runner.Repair("CreateLikes", list, true); !errors.As(err, new(*ValidationError)) {
+		t.Errorf(`expected a validation error, but got %v`, err)
+	}
+
+	// Scenario 2: Repaired as applied
+	runner.Up(list)
+
+	if err := runner.Repair("CreateLikes", list, true); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if applied.Size() != 1 || applied.GetHead().Dirty || applied.GetHead().FailedStatement != nil {
+		t.Errorf(`expected a clean migration, but got %v`, applied.Description())
+	}
+
+	// Scenario 3: Repaired as reverted
+	runner.store.Create(MarkMigrationDirty(DialectOf(runner.store), runner.schemaTable), 0, migration.Version)
+
+	if err := runner.Repair(migration.Version, list, false); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if !IsEmpty(runner.store, runner.schemaTable) {
+		t.Errorf(`expected the migration to have been removed`)
+	}
+
+	history, _ := runner.History(HistoryFilter{Action: HistoryRepair})
+
+	if len(history) != 2 {
+		t.Errorf(`expected 2 repairs, but got %v`, history.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
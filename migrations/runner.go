@@ -1,7 +1,9 @@
 package migrations
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"regexp"
@@ -14,13 +16,17 @@ import (
 )
 
 var (
-	FilePattern        = *regexp.MustCompile(`(?P<Version>^\d{20})_(?P<Name>[aA-zZ]+).yaml|sql$`)
+	FilePattern        = *regexp.MustCompile(`(?P<Version>^\d{20})_(?P<Name>[aA-zZ0-9]+)\.(yaml|sql)$`)
 	CreateTablePattern = *regexp.MustCompile(`CREATE TABLE (?P<TableName>\w+)`)
 	DropTablePattern   = *regexp.MustCompile(`(DROP TABLE (IF EXISTS )?)(?P<TableName>\w+)`)
 )
 
+// VersionLayout - Time layout of migration versions (the fraction separator is removed)
+const VersionLayout = "20060102150405.000000"
+
 /*
 Runner:
+
 	Responsible for running and reverting migrations.
 	The migration and rollback algorithm is self-contained within this type.
 
@@ -32,6 +38,35 @@ type Runner struct {
 	schemaTable string
 	store       Store
 	logger      logger.Logger
+	lockTimeout time.Duration
+	dryRun      bool
+	acceptDrift bool
+	actor       string
+	source      string
+	toolVersion string
+
+	// batch - The batch of the migrations applied by the current run. See `Runner.LastBatch()`.
+	batch int
+
+	// lockWait - How long the current run waited for the migration lock. See `Event.LockWaitMs`.
+	lockWait time.Duration
+
+	// locked - Set while the runner holds the migration lock. Actions run while it is held (i.e. by `Redo()`) do not acquire it again.
+	locked bool
+
+	// fsys - Where migration files are read from. Directories are resolved against the OS when unset.
+	fsys fs.FS
+
+	// directories, filePattern - Where migration files were last loaded from.
+	// `Up()` verifies the checksums of the applied migrations found there. See `refuseDrift()`.
+	directories []string
+	filePattern *regexp.Regexp
+
+	// events - Notified of the progress of `Up()` and `Down()`. See `SetEventHandler()`.
+	events func(Event)
+
+	// planning - Set while planning. Go migrations are not run then, since they may read the database or call external services. See `plan()`.
+	planning bool
 }
 
 // MARK: Logger
@@ -60,6 +95,42 @@ func (runner *Runner) SetStore(store Store) {
 	runner.store = store
 }
 
+// SetLockTimeout - How long `Up()` and `Down()` wait for other runners to finish. Defaults to `DefaultLockTimeout`.
+func (runner *Runner) SetLockTimeout(timeout time.Duration) {
+	runner.lockTimeout = timeout
+}
+
+// SetDryRun - Prevents the runner from upgrading the schema table. See `PlanUp()` and `PlanDown()`.
+func (runner *Runner) SetDryRun(dryRun bool) {
+	runner.dryRun = dryRun
+}
+
+// SetAcceptDrift - Makes `Up()` record the new checksums of modified migration files, instead of refusing to run.
+func (runner *Runner) SetAcceptDrift(accept bool) {
+	runner.acceptDrift = accept
+}
+
+// SetActor - Who applies migrations. Defaults to the operating system user. See `CurrentActor()`.
+func (runner *Runner) SetActor(actor string) {
+	runner.actor = actor
+}
+
+// SetSource - Where migrations are applied from (i.e. `SourceCLI` or `SourceAPI`).
+func (runner *Runner) SetSource(source string) {
+	runner.source = source
+}
+
+// SetToolVersion - The version of the tool applying migrations.
+func (runner *Runner) SetToolVersion(version string) {
+	runner.toolVersion = version
+}
+
+// SetFS - Reads migration files from a file system (i.e. an `embed.FS`) instead of the OS.
+// Directories passed to the runner are then paths within the file system.
+func (runner *Runner) SetFS(fsys fs.FS) {
+	runner.fsys = fsys
+}
+
 func (runner *Runner) GetSchemaTable() string {
 	return runner.schemaTable
 }
@@ -74,7 +145,7 @@ func (runner *Runner) GetStore() Store {
 func IsTracked(store Store, schemaTable string) bool {
 	var schema []TableSchema
 
-	err := store.Read(SchemaTableExists(schemaTable), &schema)
+	err := store.Read(SchemaTableExists(DialectOf(store), schemaTable), &schema)
 
 	if err != nil || len(schema) == 0 {
 		return false
@@ -85,7 +156,7 @@ func IsTracked(store Store, schemaTable string) bool {
 
 // MARK: - Return `true` if the schemaTable is found and has no rows.
 func IsEmpty(store Store, schemaTable string) bool {
-	var count int
+	var count []int
 
 	tracked := IsTracked(store, schemaTable)
 
@@ -95,11 +166,11 @@ func IsEmpty(store Store, schemaTable string) bool {
 
 	err := store.Read(NumberOfAppliedMigrations(schemaTable), &count)
 
-	if err != nil {
+	if err != nil || len(count) == 0 {
 		return false
 	}
 
-	return count == 0
+	return count[0] == 0
 }
 
 func IsUpToDate(store Store, schemaTable string, migrations MigrationList) bool {
@@ -125,13 +196,13 @@ func Version(store Store, schemaTable string) (MigratorVersion, bool) {
 
 	emptyVersion := MigratorVersion{}
 
-	err := store.Read(SchemaTableExists(schemaTable), &schema)
+	err := store.Read(SchemaTableExists(DialectOf(store), schemaTable), &schema)
 
 	if err != nil || len(schema) == 0 || schema[0].TableName == "" {
 		return emptyVersion, false
 	}
 
-	err = store.Read(SelectMigrationsVersion(schemaTable), &versions)
+	err = readTracked(store, schemaTable, SelectMigrationsVersion, &versions)
 
 	if err != nil {
 		fmt.Printf("%v\n", err)
@@ -146,9 +217,69 @@ func Version(store Store, schemaTable string) (MigratorVersion, bool) {
 }
 
 func StartTracking(store Store, schemaTable string) bool {
-	err := store.Create(CreateMigrationTable(schemaTable))
+	err := store.Create(CreateMigrationTable(DialectOf(store), schemaTable))
+
+	if err != nil {
+		return false
+	}
+
+	err = store.Create(CreateHistoryTable(DialectOf(store), schemaTable))
+
+	return err == nil
+}
+
+// UpgradeTracking - Adds any missing columns and tables to a schema table created by an earlier version of the tool.
+func UpgradeTracking(store Store, schemaTable string) error {
+	if !IsTracked(store, HistoryTable(schemaTable)) {
+		err := store.Create(CreateHistoryTable(DialectOf(store), schemaTable))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, column := range MissingColumns(store, schemaTable) {
+		err := store.Create(AddMigrationTableColumn(schemaTable, column))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MissingColumns - Columns the schema table lacks because it was created by an earlier version of the tool. See `UpgradeTracking`.
+func MissingColumns(store Store, schemaTable string) []TableColumn {
+	missing := []TableColumn{}
+
+	for _, column := range MigrationTableColumns() {
+		err := store.Read(SelectMigrationTableColumn(schemaTable, column), &[]string{})
 
-	return err != nil
+		if err != nil {
+			missing = append(missing, column)
+		}
+	}
+
+	return missing
+}
+
+// readTracked - Reads the schema table, reading any columns it lacks as their defaults.
+// Read-only commands do not upgrade the schema table, so it may not have been upgraded yet.
+func readTracked(store Store, schemaTable string, query func(string, ...TableColumn) string, model interface{}) error {
+	err := store.Read(query(schemaTable), model)
+
+	if err == nil {
+		return nil
+	}
+
+	missing := MissingColumns(store, schemaTable)
+
+	if len(missing) == 0 {
+		return err
+	}
+
+	return store.Read(query(schemaTable, missing...), model)
 }
 
 func StopTracking(store Store, schemaTable string) bool {
@@ -168,21 +299,11 @@ func (runner *Runner) Generate(format, filecontent, name, directory string) Migr
 	var content []byte
 	var err error
 
-	exclusionPattern := regexp.MustCompile(`(\-?\d{4} \-?\d{2} m=\+\d{1}.\d{9})|(\-)|(\W+)|(\:)|(\.)`)
-
 	/*
 		Input : 2022-05-04 18:49:19.478478 -0400 -04 m=+0.001942418
 		Output: 20220504184919478478 (20 characters in total)
 	*/
-	now := time.Now().String()
-	timestamp := exclusionPattern.ReplaceAllLiteralString(now, ``)
-
-	// BUG: Regex needs updating. Timestamp sometimes returns < 20 characters.
-	// FIX: Use a constant for this value instead.
-	for len(timestamp) < 20 {
-		now = time.Now().String()
-		timestamp = exclusionPattern.ReplaceAllLiteralString(now, ``)
-	}
+	timestamp := strings.Replace(time.Now().Format(VersionLayout), ".", "", 1)
 
 	filename := fmt.Sprintf("%v_%v.%v", timestamp, strcase.ToSnake(name), format)
 
@@ -220,7 +341,16 @@ func (runner *Runner) Generate(format, filecontent, name, directory string) Migr
 func (runner *Runner) Up(migrations MigrationList) error {
 	runner.beforeAction()
 
+	if err := runner.refuseDirty(); err != nil {
+		return err
+	}
+
 	if migrations.Size() == 0 {
+		// NOTE: Drift is refused (or accepted) even when there is nothing to run
+		if err := runner.refuseDrift(runner.store, migrations); err != nil {
+			return err
+		}
+
 		runner.LogInfo("No migrations to run.")
 		return nil
 	}
@@ -232,27 +362,58 @@ func (runner *Runner) Up(migrations MigrationList) error {
 		return new(ValidationError)
 	}
 
+	release, err := runner.lock()
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	// NOTE: Another runner may have failed part-way while this one waited for the lock
+	if err := runner.refuseDirty(); err != nil {
+		return err
+	}
+
+	if err := runner.refuseDrift(runner.store, migrations); err != nil {
+		return err
+	}
+
 	if IsUpToDate(runner.store, runner.schemaTable, migrations) {
 		runner.LogInfo("Migrations are up-to-date.")
 		return nil
 	}
 
+	// NOTE: Another runner may have applied some of these migrations while this one waited for the lock
+	migrations = runner.exclude(migrations, true)
 	migration := migrations.GetHead()
 
+	runner.batch = runner.LastBatch() + 1
+
+	completed, runStarted := 0, time.Now()
+	runner.emit(runEvent(EventRunStarted, "up", migrations.Size(), 0), runStarted, nil)
+
 	for migration != nil {
-		err := runner.performMigration(*migration)
+		started := time.Now()
+		runner.emit(migrationEvent(EventStarted, "up", *migration), started, nil)
+
+		err := runner.applyMigration(*migration)
 
 		if err != nil {
-			_ = runner.removeMigrationFromSchema(*migration, runner.schemaTable)
+			runner.emit(migrationEvent(EventFailed, "up", *migration), started, err)
+			runner.emit(runEvent(EventRunFinished, "up", migrations.Size(), completed), runStarted, err)
 			runner.LogError(fmt.Sprintf("\nMigration '%v' (%v) failed.\n%v \n", migration.Name, migration.Version, err))
 			return err
 		}
 
-		runner.registerMigration(*migration, runner.schemaTable)
+		runner.emit(migrationEvent(EventSucceeded, "up", *migration), started, nil)
+		runner.logger.CacheMessage(*migration)
 
+		completed++
 		migration = migration.Next()
 	}
 
+	runner.emit(runEvent(EventRunFinished, "up", migrations.Size(), completed), runStarted, nil)
 	runner.logger.ReleaseCachedMessages(os.Stdout)
 
 	return nil
@@ -261,6 +422,10 @@ func (runner *Runner) Up(migrations MigrationList) error {
 func (runner *Runner) Down(migrations MigrationList) error {
 	runner.beforeAction()
 
+	if err := runner.refuseDirty(); err != nil {
+		return err
+	}
+
 	valid, reason := Validate(migrations)
 
 	if !valid {
@@ -268,31 +433,189 @@ func (runner *Runner) Down(migrations MigrationList) error {
 		return new(ValidationError)
 	}
 
+	release, err := runner.lock()
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	// NOTE: Another runner may have failed part-way while this one waited for the lock
+	if err := runner.refuseDirty(); err != nil {
+		return err
+	}
+
 	if IsEmpty(runner.store, runner.schemaTable) {
 		runner.LogInfo("No migrations to rollback.")
 		return nil
 	}
 
+	// NOTE: Another runner may have reverted some of these migrations while this one waited for the lock
+	migrations = runner.exclude(migrations, false)
 	migration := migrations.GetHead()
 
+	completed, runStarted := 0, time.Now()
+	runner.emit(runEvent(EventRunStarted, "down", migrations.Size(), 0), runStarted, nil)
+
 	for migration != nil {
-		// Perform the migration's rollback instruction (down)
+		started := time.Now()
+		runner.emit(migrationEvent(EventStarted, "down", *migration), started, nil)
 
-		for _, change := range migration.Changes.Down {
-			err := runner.store.Delete(change)
+		err := runner.revertMigration(*migration)
 
-			if err != nil {
-				runner.LogError(fmt.Sprintf("\nRollback '%v' (%v) failed.\n%v \n", migration.Name, migration.Version, err))
-				return err
+		if err != nil {
+			runner.emit(migrationEvent(EventFailed, "down", *migration), started, err)
+			runner.emit(runEvent(EventRunFinished, "down", migrations.Size(), completed), runStarted, err)
+			runner.LogError(fmt.Sprintf("\nRollback '%v' (%v) failed.\n%v \n", migration.Name, migration.Version, err))
+			return err
+		}
+
+		runner.emit(migrationEvent(EventSucceeded, "down", *migration), started, nil)
+		runner.logger.CacheMessage(*migration)
+
+		completed++
+		migration = migration.Next()
+	}
+
+	runner.emit(runEvent(EventRunFinished, "down", migrations.Size(), completed), runStarted, nil)
+	runner.logger.ReleaseCachedMessages(os.Stdout)
+
+	return nil
+}
+
+// Redo - Reverts and re-applies the last `steps` applied migrations. Used to iterate on migrations during development.
+// Migrations are re-applied from their files, so the files of every one of them must exist.
+// The migration lock is held from the rollback to the migration, so other runners cannot migrate in between.
+func (runner *Runner) Redo(steps int, directories []string, filePattern *regexp.Regexp) error {
+	runner.beforeAction()
+
+	release, err := runner.lock()
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	applied := runner.AppliedMigrations(directories, filePattern, false)
+	applied.Reverse()
+	applied = applied.Take(steps)
+
+	if applied.Size() == 0 {
+		runner.LogInfo("No applied migrations to redo.")
+		return nil
+	}
+
+	files, err := runner.load(directories, filePattern)
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("An error occurred.\nError: %v\n", err))
+		return err
+	}
+
+	available := files.ToMap()
+
+	// NOTE: Migrations are reapplied from their files, so only the drift of the other migrations is refused
+	if !runner.acceptDrift {
+		drifted := Migrations{}
+
+		for _, migration := range runner.drifted(MigrationList{}) {
+			if _, redone := applied.Find(migration.Version); !redone {
+				drifted = append(drifted, migration)
 			}
 		}
 
-		err := runner.removeMigrationFromSchema(*migration, runner.schemaTable)
+		if drifted.Len() != 0 {
+			runner.LogError(fmt.Sprintf("Applied migrations were modified:\n%v", drifted.Description()))
+			return &ChecksumMismatchError{Versions: drifted.Versions()}
+		}
+	}
 
-		if err != nil {
-			return err
+	reverted := MigrationList{}
+
+	for curr := applied.GetHead(); curr != nil; curr = curr.Next() {
+		migration, found := available[curr.Version]
+
+		if !found {
+			runner.LogError(fmt.Sprintf("No migration file found for '%v' (%v).", curr.Name, curr.Version))
+			return new(ValidationError)
 		}
 
+		migration.next = nil
+		migration.previous = nil
+
+		reverted.Insert(&migration)
+	}
+
+	if err := runner.Down(reverted); err != nil {
+		return err
+	}
+
+	reapplied := reverted.Take(reverted.Size())
+	reapplied.Reverse()
+
+	return runner.Up(reapplied)
+}
+
+// Baseline - Records the migrations as applied without running them.
+// Used to adopt the tool on a database whose schema already includes the changes of these migrations.
+func (runner *Runner) Baseline(migrations MigrationList) error {
+	runner.beforeAction()
+
+	if migrations.Size() == 0 {
+		runner.LogInfo("No migrations to baseline.")
+		return nil
+	}
+
+	valid, reason := Validate(migrations)
+
+	if !valid {
+		runner.LogError(reason)
+		return new(ValidationError)
+	}
+
+	release, err := runner.lock()
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	if !IsTracked(runner.store, runner.schemaTable) && !StartTracking(runner.store, runner.schemaTable) {
+		runner.LogError("Unable to create schema table.")
+		return new(EngineError)
+	}
+
+	migrations = runner.exclude(migrations, true)
+
+	err = runner.store.Transaction(func(store Store) error {
+		migration := migrations.GetHead()
+
+		for migration != nil {
+			if err := runner.registerBaseline(store, *migration, runner.schemaTable); err != nil {
+				return err
+			}
+
+			migration = migration.Next()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("\nBaseline failed.\n%v \n", err))
+		return err
+	}
+
+	migration := migrations.GetHead()
+
+	for migration != nil {
+		migration.Baselined = true
+		runner.record(*migration, HistoryBaseline, nil)
+		runner.logger.CacheMessage(*migration)
+
 		migration = migration.Next()
 	}
 
@@ -301,11 +624,15 @@ func (runner *Runner) Down(migrations MigrationList) error {
 	return nil
 }
 
-func (runner *Runner) PendingMigrations(directory string, filePattern *regexp.Regexp) MigrationList {
+func (runner *Runner) PendingMigrations(directories []string, filePattern *regexp.Regexp) MigrationList {
 	runner.beforeAction()
 
-	files := LoadFiles(directory, filePattern)
-	list := BuildMigrations(files, directory, filePattern)
+	list, err := runner.load(directories, filePattern)
+
+	if err != nil {
+		runner.LogError(fmt.Sprintf("An error occurred.\nError: %v\n", err))
+		return MigrationList{}
+	}
 
 	migrated := Migrations{}
 	res := MigrationList{}
@@ -315,7 +642,7 @@ func (runner *Runner) PendingMigrations(directory string, filePattern *regexp.Re
 		return list
 	}
 
-	err := runner.store.Read(SelectMigrations(runner.schemaTable), &migrated)
+	err = readTracked(runner.store, runner.schemaTable, SelectMigrations, &migrated)
 
 	if err != nil {
 		runner.LogError(fmt.Sprintf("An error occurred.\nError: %v\n", err))
@@ -330,13 +657,18 @@ func (runner *Runner) PendingMigrations(directory string, filePattern *regexp.Re
 
 		if !applied {
 			res.Insert(&Migration{
-				Changes:  curr.Changes,
-				Engine:   curr.Engine,
-				FileName: curr.FileName,
-				Id:       curr.Id,
-				Name:     curr.Name,
-				Schema:   curr.Schema,
-				Version:  curr.Version,
+				Changes:            curr.Changes,
+				Engine:             curr.Engine,
+				FileName:           curr.FileName,
+				Path:               curr.Path,
+				Id:                 curr.Id,
+				Name:               curr.Name,
+				Schema:             curr.Schema,
+				Version:            curr.Version,
+				DisableTransaction: curr.DisableTransaction,
+				UpFunc:             curr.UpFunc,
+				DownFunc:           curr.DownFunc,
+				Baselined:          curr.Baselined,
 			})
 		}
 
@@ -346,7 +678,7 @@ func (runner *Runner) PendingMigrations(directory string, filePattern *regexp.Re
 	return res
 }
 
-func (runner *Runner) AppliedMigrations(directory string, filePattern *regexp.Regexp, loadFromDir bool) MigrationList {
+func (runner *Runner) AppliedMigrations(directories []string, filePattern *regexp.Regexp, loadFromDir bool) MigrationList {
 	runner.beforeAction()
 
 	migrated := Migrations{}
@@ -357,25 +689,50 @@ func (runner *Runner) AppliedMigrations(directory string, filePattern *regexp.Re
 		return MigrationList{}
 	}
 
-	err := runner.store.Read(SelectMigrations(runner.schemaTable), &migrated)
+	err := readTracked(runner.store, runner.schemaTable, SelectMigrations, &migrated)
 
 	if err != nil {
 		runner.LogError(fmt.Sprintf("An error occurred.\nError: %v\n", err))
 	}
 
+	// NOTE: Migration files are indexed by version, since they can be written in any supported format
+	files := map[string]Migration{}
+
+	if loadFromDir {
+		if list, err := runner.load(directories, filePattern); err == nil {
+			files = list.ToMap()
+		}
+	}
+
 	for _, curr := range migrated {
 		m := Migration{
-			Engine:   runner.store.Name(),
-			Id:       curr.Id,
-			Name:     curr.Name,
-			Version:  curr.Version,
-			FileName: fmt.Sprintf(`%v_%v.yaml`, curr.Version, strcase.ToSnake(curr.Name)),
+			Engine:          runner.store.Name(),
+			Id:              curr.Id,
+			Name:            curr.Name,
+			Version:         curr.Version,
+			FileName:        fmt.Sprintf(`%v_%v.yaml`, curr.Version, strcase.ToSnake(curr.Name)),
+			Checksum:        curr.Checksum,
+			Baselined:       curr.Baselined,
+			Dirty:           curr.Dirty,
+			FailedStatement: curr.FailedStatement,
+			Batch:           curr.Batch,
+			AppliedAt:       curr.AppliedAt,
+			Execution:       curr.Execution,
 		}
 
-		// NOTE: Create a representation of the underlying file and
-		// use it to load the file stored on the disk
-		if loadFromDir {
-			m.Load(MigrationFile{name: m.FileName}, directory, filePattern)
+		// NOTE: Use the file stored on the disk (or the registered Go migration) for the changes
+		if file, found := files[m.Version]; found {
+			m.FileName = file.FileName
+			m.Path = file.Path
+			m.Schema = file.Schema
+			m.Engine = file.Engine
+			m.Changes = file.Changes
+			m.DisableTransaction = file.DisableTransaction
+			m.UpFunc = file.UpFunc
+			m.DownFunc = file.DownFunc
+
+			// NOTE: Migrations applied before checksums were recorded cannot drift
+			m.Drifted = m.Checksum != "" && m.Checksum != m.Changes.Checksum()
 		}
 
 		res.Insert(&m)
@@ -384,6 +741,66 @@ func (runner *Runner) AppliedMigrations(directory string, filePattern *regexp.Re
 	return res
 }
 
+// DriftedMigrations - Returns the applied migrations whose files were modified after being applied.
+func (runner *Runner) DriftedMigrations(directories []string, filePattern *regexp.Regexp) Migrations {
+	drifted := Migrations{}
+
+	loadFromDir := true
+	applied := runner.AppliedMigrations(directories, filePattern, loadFromDir)
+
+	for _, migration := range applied.ToSlice() {
+		if migration.Drifted {
+			drifted = append(drifted, migration)
+		}
+	}
+
+	return drifted
+}
+
+// VerifyChecksums - Returns an error if any applied migration file was modified after being applied.
+func (runner *Runner) VerifyChecksums(directories []string, filePattern *regexp.Regexp) error {
+	drifted := runner.DriftedMigrations(directories, filePattern)
+
+	if drifted.Len() == 0 {
+		return nil
+	}
+
+	runner.LogError(fmt.Sprintf("Applied migrations were modified:\n%v", drifted.Description()))
+	return &ChecksumMismatchError{Versions: drifted.Versions()}
+}
+
+// AcceptDrift - Records the current checksum of the provided migrations, accepting any changes made to their files.
+func (runner *Runner) AcceptDrift(migrations Migrations) error {
+	runner.beforeAction()
+
+	release, err := runner.lock()
+
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return runner.recordChecksums(runner.store, migrations)
+}
+
+// LastBatch - Returns the batch of the most recent run of `Runner.Up()`, or 0 if no batches were recorded.
+func (runner *Runner) LastBatch() int {
+	var batches []int
+
+	if !IsTracked(runner.store, runner.schemaTable) {
+		return 0
+	}
+
+	err := runner.store.Read(SelectLastBatch(runner.schemaTable), &batches)
+
+	if err != nil || len(batches) == 0 {
+		return 0
+	}
+
+	return batches[0]
+}
+
 func (runner *Runner) Version() (MigratorVersion, bool) {
 	runner.beforeAction()
 	return Version(runner.store, runner.schemaTable)
@@ -403,9 +820,118 @@ func (runner *Runner) beforeAction() {
 	}
 }
 
-func (runner *Runner) performMigration(migration Migration) error {
-	for _, change := range migration.Changes.Up {
-		err := runner.store.Create(change)
+// files - The file system rooted at a directory of migration files.
+func (runner *Runner) files(directory string) (fs.FS, error) {
+	if runner.fsys == nil {
+		return DirFS(directory), nil
+	}
+
+	if directory == "" {
+		directory = "."
+	}
+
+	return fs.Sub(runner.fsys, directory)
+}
+
+// load - Loads the migrations found in any of the directories, merged with registered Go migrations.
+func (runner *Runner) load(directories []string, filePattern *regexp.Regexp) (MigrationList, error) {
+	loaded := RegisteredMigrations()
+
+	runner.directories, runner.filePattern = directories, filePattern
+
+	for _, directory := range directories {
+		fsys, err := runner.files(directory)
+
+		if err != nil {
+			return MigrationList{}, err
+		}
+
+		loaded = append(loaded, loadMigrations(fsys, LoadFilesFS(fsys, filePattern), directory, filePattern)...)
+	}
+
+	return newMigrationList(loaded), nil
+}
+
+// Check - Returns the error `Up()` would refuse to apply the migrations with: dirty migrations, or applied migrations that were modified (unless drift is accepted).
+// Unlike `PlanUp()`, no migration is planned and the database is not modified.
+func (runner *Runner) Check(migrations MigrationList) error {
+	runner.beforeAction()
+
+	if err := runner.refuseDirty(); err != nil {
+		return err
+	}
+
+	// NOTE: Accepted drift is recorded by `Up()`
+	if runner.acceptDrift {
+		return nil
+	}
+
+	return runner.refuseDrift(runner.store, migrations)
+}
+
+// refuseDrift - Returns an error if applied migrations were modified after being applied, unless drift is accepted.
+// Accepted drift is recorded in the store (a recorder, when planning).
+func (runner *Runner) refuseDrift(store Store, migrations MigrationList) error {
+	drifted := runner.drifted(migrations)
+
+	if drifted.Len() == 0 {
+		return nil
+	}
+
+	if !runner.acceptDrift {
+		runner.LogError(fmt.Sprintf("Applied migrations were modified:\n%v", drifted.Description()))
+		return &ChecksumMismatchError{Versions: drifted.Versions()}
+	}
+
+	return runner.recordChecksums(store, drifted)
+}
+
+// drifted - Returns the applied migrations whose files were modified after being applied.
+// Files are looked up among the given migrations and in the directories migrations were last loaded from.
+func (runner *Runner) drifted(migrations MigrationList) Migrations {
+	drifted := Migrations{}
+	files := map[string]Migration{}
+
+	if !IsTracked(runner.store, runner.schemaTable) {
+		return drifted
+	}
+
+	if runner.filePattern != nil {
+		if loaded, err := runner.load(runner.directories, runner.filePattern); err == nil {
+			files = loaded.ToMap()
+		}
+	}
+
+	for curr := migrations.GetHead(); curr != nil; curr = curr.Next() {
+		files[curr.Version] = *curr
+	}
+
+	applied := Migrations{}
+
+	if err := readTracked(runner.store, runner.schemaTable, SelectMigrations, &applied); err != nil {
+		return drifted
+	}
+
+	for _, migration := range applied {
+		file, found := files[migration.Version]
+
+		// NOTE: Migrations applied before checksums were recorded cannot drift
+		if found && migration.Checksum != "" && migration.Checksum != file.Changes.Checksum() {
+			drifted = append(drifted, file)
+		}
+	}
+
+	return drifted
+}
+
+// recordChecksums - Records the current checksum of the migrations in the schema table.
+func (runner *Runner) recordChecksums(store Store, migrations Migrations) error {
+	for _, migration := range migrations {
+		err := store.Create(
+			UpdateMigrationChecksum(DialectOf(runner.store), runner.schemaTable),
+			migration.Changes.Checksum(),
+			migration.Version,
+		)
 
 		if err != nil {
 			return err
@@ -415,39 +941,260 @@ func (runner *Runner) performMigration(migration Migration) error {
 	return nil
 }
 
-func (runner *Runner) registerMigration(migration Migration, table string) error {
-	err := runner.store.Create(
-		CreateMigrationEntry(table),
-		migration.Version,
-		migration.Name,
-	)
+// lock - Acquires the migration lock, logging a descriptive error if it cannot be acquired in time.
+// Once locked, a schema table created by an earlier version of the tool is upgraded, so that runners do not race to upgrade it.
+// Read-only actions do not lock, nor upgrade the table. See `readTracked`.
+func (runner *Runner) lock() (func() error, error) {
+	if runner.locked {
+		return func() error { return nil }, nil
+	}
+
+	started := time.Now()
+	release, err := runner.acquireLock()
+	runner.lockWait = time.Since(started)
 
 	if err != nil {
+		runner.LogError(fmt.Sprintf(
+			"Unable to acquire the migration lock. Another migration may be in progress.\nError: %v\n",
+			err,
+		))
+
+		lockError := new(LockError)
+		errors.As(err, &lockError)
+
+		return nil, lockError
+	}
+
+	if IsTracked(runner.store, runner.schemaTable) {
+		if err = UpgradeTracking(runner.store, runner.schemaTable); err != nil {
+			runner.LogError(fmt.Sprintf("Unable to upgrade schema table.\nError: %v\n", err))
+			release()
+
+			return nil, err
+		}
+	}
+
+	runner.locked = true
+
+	return func() error {
+		runner.locked = false
+		return release()
+	}, nil
+}
+
+// exclude - Removes the migrations that are (applied == true) or are not (applied == false) registered in the schema table.
+func (runner *Runner) exclude(migrations MigrationList, applied bool) MigrationList {
+	migrated := Migrations{}
+	res := MigrationList{}
+
+	err := readTracked(runner.store, runner.schemaTable, SelectMigrations, &migrated)
+
+	if err != nil {
+		return migrations
+	}
+
+	migratedHash := migrated.ToHash()
+
+	curr := migrations.GetHead()
+
+	for curr != nil {
+		if _, found := migratedHash[curr.Version]; found != applied {
+			res.Insert(&Migration{
+				Changes:            curr.Changes,
+				Engine:             curr.Engine,
+				FileName:           curr.FileName,
+				Path:               curr.Path,
+				Id:                 curr.Id,
+				Name:               curr.Name,
+				Schema:             curr.Schema,
+				Version:            curr.Version,
+				DisableTransaction: curr.DisableTransaction,
+				UpFunc:             curr.UpFunc,
+				DownFunc:           curr.DownFunc,
+				Baselined:          curr.Baselined,
+			})
+		}
+
+		curr = curr.Next()
+	}
+
+	return res
+}
+
+// applyMigration - Runs the migration's changes (up) and registers it in the schema table.
+// Unless the migration opts out, both steps are committed or rolled back together.
+// The outcome is recorded in the history table either way.
+func (runner *Runner) applyMigration(migration Migration) error {
+	var err error
+
+	if migration.DisableTransaction {
+		start := time.Now()
+		err = runner.performMigration(runner.store, migration)
+		migration.DurationMs = time.Since(start).Milliseconds()
+
+		if err == nil {
+			err = runner.registerMigration(runner.store, migration, runner.schemaTable)
+		}
+	} else {
+		err = runner.store.Transaction(func(tx Store) error {
+			start := time.Now()
+			err := runner.performMigration(tx, migration)
+			migration.DurationMs = time.Since(start).Milliseconds()
+
+			if err != nil {
+				return err
+			}
+
+			return runner.registerMigration(tx, migration, runner.schemaTable)
+		})
+	}
+
+	if err != nil {
+		runner.record(migration, HistoryApplyFailure, err)
+
+		if !runner.isAtomic(migration) {
+			runner.markDirty(migration, err, true)
+		}
+
 		return err
 	}
 
-	runner.logger.CacheMessage(migration)
+	runner.record(migration, HistoryApply, nil)
 	return nil
 }
 
-func (runner *Runner) removeMigrationFromSchema(migration Migration, table string) error {
-	err := runner.store.Delete(
-		DeleteMigrationEntry(table),
-		migration.Version,
-		migration.Name,
-	)
+// revertMigration - Runs the migration's rollback instructions (down) and removes it from the schema table.
+// Unless the migration opts out, both steps are committed or rolled back together.
+// The outcome is recorded in the history table either way.
+func (runner *Runner) revertMigration(migration Migration) error {
+	var err error
+
+	if migration.DisableTransaction {
+		start := time.Now()
+		err = runner.performRollback(runner.store, migration)
+		migration.DurationMs = time.Since(start).Milliseconds()
+
+		if err == nil {
+			err = runner.removeMigrationFromSchema(runner.store, migration, runner.schemaTable)
+		}
+	} else {
+		err = runner.store.Transaction(func(tx Store) error {
+			start := time.Now()
+			err := runner.performRollback(tx, migration)
+			migration.DurationMs = time.Since(start).Milliseconds()
+
+			if err != nil {
+				return err
+			}
+
+			return runner.removeMigrationFromSchema(tx, migration, runner.schemaTable)
+		})
+	}
 
 	if err != nil {
+		runner.record(migration, HistoryRevertFailure, err)
+
+		if !runner.isAtomic(migration) {
+			runner.markDirty(migration, err, false)
+		}
+
 		return err
 	}
 
-	runner.logger.CacheMessage(migration)
+	runner.record(migration, HistoryRevert, nil)
+	return nil
+}
+
+func (runner *Runner) performMigration(store Store, migration Migration) error {
+	if migration.IsGo() {
+		if runner.planning {
+			return nil
+		}
+
+		return migration.UpFunc(store)
+	}
+
+	for index, change := range migration.Changes.Up {
+		started := time.Now()
+		err := store.Create(change)
+
+		runner.emit(statementEvent("up", migration, index), started, err)
+
+		if err != nil {
+			return StatementError{Index: index, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (runner *Runner) performRollback(store Store, migration Migration) error {
+	if migration.IsGo() {
+		if runner.planning {
+			return nil
+		}
+
+		return migration.DownFunc(store)
+	}
+
+	for index, change := range migration.Changes.Down {
+		started := time.Now()
+		err := store.Delete(change)
+
+		runner.emit(statementEvent("down", migration, index), started, err)
+
+		if err != nil {
+			return StatementError{Index: index, Err: err}
+		}
+	}
+
 	return nil
 }
 
+func (runner *Runner) registerMigration(store Store, migration Migration, table string) error {
+	execution := runner.execution()
+
+	return store.Create(
+		CreateMigrationEntry(DialectOf(store), table),
+		migration.Version,
+		migration.Name,
+		migration.Changes.Checksum(),
+		migration.DurationMs,
+		execution.AppliedBy,
+		execution.Host,
+		execution.ToolVersion,
+		execution.Source,
+		runner.batch,
+	)
+}
+
+func (runner *Runner) registerBaseline(store Store, migration Migration, table string) error {
+	execution := runner.execution()
+
+	return store.Create(
+		CreateBaselineEntry(DialectOf(store), table),
+		migration.Version,
+		migration.Name,
+		migration.Changes.Checksum(),
+		int64(0),
+		execution.AppliedBy,
+		execution.Host,
+		execution.ToolVersion,
+		execution.Source,
+	)
+}
+
+func (runner *Runner) removeMigrationFromSchema(store Store, migration Migration, table string) error {
+	return store.Delete(
+		DeleteMigrationEntry(DialectOf(store), table),
+		migration.Version,
+		migration.Name,
+	)
+}
+
 func (runner *Runner) generateSQLTemplate(filecontent string, migration Migration) (content []byte) {
-	topLine := fmt.Sprintf("-- liquibase formatted sql\n-- changeset liquibase:%v\n\n", migration.Version)
-	bottomLine := "\n\n-- rollback STATEMENT... \n"
+	topLine := fmt.Sprintf("%v Engine %v\n\n%v Up\n", SQLDirectivePrefix, migration.Engine, SQLDirectivePrefix)
+	bottomLine := fmt.Sprintf("\n\n%v Down\n", SQLDirectivePrefix)
 
 	content = append(content, []byte(topLine)...)
 	content = append(content, []byte(filecontent)...)
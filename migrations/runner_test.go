@@ -1,8 +1,13 @@
 package migrations
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/oleoneto/dm/stores"
 )
@@ -151,6 +156,184 @@ func TestStartAndStopTrackingStore(t *testing.T) {
 	t.Cleanup(rebuildDatabaseSchema)
 }
 
+func TestUpgradeTracking(t *testing.T) {
+	table := "schema_migrations"
+
+	// Scenario 1: A schema table created before checksums were recorded
+	testPostgresStore.Create(`CREATE TABLE schema_migrations (
+		id SERIAL,
+		version varchar UNIQUE NOT NULL,
+		name varchar UNIQUE NOT NULL,
+		created_at timestamp NOT NULL DEFAULT now(),
+
+		PRIMARY KEY(id)
+	);`)
+
+	err := UpgradeTracking(testPostgresStore, table)
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	migrated := Migrations{}
+	err = testPostgresStore.Read(SelectMigrations(table), &migrated)
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	// Scenario 2: An up-to-date schema table is left untouched
+	err = UpgradeTracking(testPostgresStore, table)
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerReadsOutdatedSchemaTable(t *testing.T) {
+	runner := testRunner()
+	runner.SetFS(defaultMigrationFS())
+	list := defaultMigrationList()
+
+	// NOTE: A schema table created before checksums were recorded
+	testPostgresStore.Create(`CREATE TABLE test_migrations (
+		id SERIAL,
+		version varchar UNIQUE NOT NULL,
+		name varchar UNIQUE NOT NULL,
+		created_at timestamp NOT NULL DEFAULT now(),
+
+		PRIMARY KEY(id)
+	);`)
+
+	testPostgresStore.Create(`INSERT INTO test_migrations (id, version, name) VALUES (1, '20221231054530129328', 'CreateUsers');`)
+
+	// Scenario 1: Read-only actions read the missing columns as their defaults, without upgrading the table
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if applied.Size() != 1 || applied.GetHead().Checksum != "" || applied.GetHead().Dirty {
+		t.Errorf(`expected 1 applied migration, but got %v`, applied.ToSlice())
+	}
+
+	if version, tracked := runner.Version(); !tracked || version.Version != "20221231054530129328" {
+		t.Errorf(`expected version 20221231054530129328, but got %v`, version.Version)
+	}
+
+	if pending := runner.PendingMigrations([]string{"db/migrations"}, &FilePattern); pending.Size() != 1 {
+		t.Errorf(`expected 1 pending migration, but got %v`, pending.Description())
+	}
+
+	if missing := MissingColumns(runner.store, runner.schemaTable); len(missing) != len(MigrationTableColumns()) {
+		t.Errorf(`expected the schema table not to be upgraded, but got %v missing column(s)`, len(missing))
+	}
+
+	// Scenario 2: Actions that write to the schema table upgrade it once they hold the migration lock
+	sequence, _ := list.Find("CreateUsers")
+
+	other := testRunner()
+	release, _ := other.acquireLock()
+
+	runner.SetLockTimeout(time.Second)
+
+	if err := runner.Baseline(sequence); !errors.As(err, new(*LockError)) {
+		t.Errorf(`expected %T, but got %v`, new(*LockError), err)
+	}
+
+	if missing := MissingColumns(runner.store, runner.schemaTable); len(missing) != len(MigrationTableColumns()) {
+		t.Errorf(`expected the schema table not to be upgraded without the lock, but got %v missing column(s)`, len(missing))
+	}
+
+	release()
+	runner.Baseline(sequence)
+
+	if missing := MissingColumns(runner.store, runner.schemaTable); len(missing) != 0 {
+		t.Errorf(`expected the schema table to be upgraded, but got %v missing column(s)`, len(missing))
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerAcquireLock(t *testing.T) {
+	runner := testRunner()
+	runner.SetLockTimeout(time.Second)
+
+	// Scenario 1: The lock is available
+	release, err := runner.acquireLock()
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	// Scenario 2: The lock is held by another runner
+	_, err = runner.acquireLock()
+
+	if err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+
+	// Scenario 3: The lock was released
+	release()
+
+	release, err = runner.acquireLock()
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	release()
+}
+
+func TestRunnerAcquireLockWithLockTable(t *testing.T) {
+	runner := testRunner()
+	runner.SetStore(stores.SQLite3{URL: filepath.Join(t.TempDir(), "test.db")})
+	runner.SetLockTimeout(time.Second)
+
+	// Scenario 1: The lock is available
+	release, err := runner.acquireLock()
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	// Scenario 2: The lock is held by another runner, which is named in the error
+	runner.SetActor("alice")
+	_, err = runner.acquireLock()
+
+	var lockError *LockError
+
+	if !errors.As(err, &lockError) || lockError.Holder == nil {
+		t.Fatalf(`expected %T naming the holder, but got %v`, lockError, err)
+	}
+
+	if holder := lockError.Holder; holder.PID != os.Getpid() || holder.Owner == "" || holder.AcquiredAt.IsZero() {
+		t.Errorf(`expected the holder to be recorded, but got %+v`, holder)
+	}
+
+	// Scenario 3: The lock was forcefully released
+	err = runner.ReleaseLock()
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	if holder, _ := runner.LockHolder(); holder != nil {
+		t.Errorf(`expected the lock to be released, but got %+v`, holder)
+	}
+
+	release, err = runner.acquireLock()
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	if holder, _ := runner.LockHolder(); holder == nil || holder.Owner != "alice" {
+		t.Errorf(`expected alice to hold the lock, but got %+v`, holder)
+	}
+
+	release()
+}
+
 // =======================================
 // MARK: - Logger
 
@@ -203,7 +386,7 @@ func TestRunnerPerformMigration(t *testing.T) {
 	runner := testRunner()
 
 	// Scenario 1: Given a valid migration, write it to the database.
-	err := runner.performMigration(*defaultMigrationList().head)
+	err := runner.performMigration(runner.store, *defaultMigrationList().head)
 
 	if err != nil {
 		t.Errorf(`expected no errors, but got %v`, err)
@@ -234,7 +417,7 @@ func TestRunnerPerformMigration(t *testing.T) {
 	}
 
 	for _, migration := range invalidMigrations {
-		err = runner.performMigration(migration)
+		err = runner.performMigration(runner.store, migration)
 
 		if err == nil {
 			t.Errorf(`expected an error, but got %v`, err)
@@ -248,24 +431,24 @@ func TestRunnerRegisterMigration(t *testing.T) {
 	runner := testRunner()
 
 	// Scenario 1: Given a migration, it should not be added to the schema table if it does not exist
-	err := runner.registerMigration(*defaultMigrationList().head, runner.schemaTable)
+	err := runner.registerMigration(runner.store, *defaultMigrationList().head, runner.schemaTable)
 
 	if err == nil {
 		t.Errorf(`expected a database error, but got %v`, err)
 	}
 
 	// Create schema table
-	testPostgresStore.Create(CreateMigrationTable(runner.schemaTable))
+	testPostgresStore.Create(CreateMigrationTable(PostgreSQLDialect, runner.schemaTable))
 
 	// Scenario 2: Given a migration, it should be added to the schema table
-	err = runner.registerMigration(*defaultMigrationList().head, runner.schemaTable)
+	err = runner.registerMigration(runner.store, *defaultMigrationList().head, runner.schemaTable)
 
 	if err != nil {
 		t.Errorf(`expected no errors, but got %v`, err)
 	}
 
 	// Scenario 3: Given a duplicate migration, it should be added to the schema table
-	err = runner.registerMigration(*defaultMigrationList().head, runner.schemaTable)
+	err = runner.registerMigration(runner.store, *defaultMigrationList().head, runner.schemaTable)
 
 	if err == nil {
 		t.Errorf(`expected a database error, but got %v`, err)
@@ -274,21 +457,52 @@ func TestRunnerRegisterMigration(t *testing.T) {
 	t.Cleanup(rebuildDatabaseSchema)
 }
 
+func TestRunnerRegisterMigrationExecution(t *testing.T) {
+	runner := testRunner()
+	runner.SetActor("deploy")
+	runner.SetSource(SourceCLI)
+	runner.SetToolVersion("3.1.0")
+
+	testPostgresStore.Create(CreateMigrationTable(PostgreSQLDialect, runner.schemaTable))
+
+	migration := *defaultMigrationList().head
+	migration.DurationMs = 42
+
+	err := runner.registerMigration(runner.store, migration, runner.schemaTable)
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	list := runner.AppliedMigrations(nil, &FilePattern, false)
+	applied := list.GetHead()
+
+	if applied.DurationMs != 42 || applied.AppliedBy != "deploy" || applied.Source != SourceCLI || applied.ToolVersion != "3.1.0" {
+		t.Errorf(`expected execution to be recorded, but got %v`, applied.Execution)
+	}
+
+	if applied.AppliedAt == nil {
+		t.Errorf(`expected applied_at to be set`)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
 func TestRunnerRemoveMigrationFromSchema(t *testing.T) {
 	runner := testRunner()
 
 	// Create schema table
-	testPostgresStore.Create(CreateMigrationTable(runner.schemaTable))
+	testPostgresStore.Create(CreateMigrationTable(PostgreSQLDialect, runner.schemaTable))
 
 	// Scenario 1: Given a migration, remove it from the schema table
-	err := runner.removeMigrationFromSchema(*defaultMigrationList().head, runner.schemaTable)
+	err := runner.removeMigrationFromSchema(runner.store, *defaultMigrationList().head, runner.schemaTable)
 
 	if err != nil {
 		t.Errorf(`expected no errors, but got %v`, err)
 	}
 
 	// Scenario 2: Given a migration, error if a non-existing schema table is provided
-	err = runner.removeMigrationFromSchema(*defaultMigrationList().head, "wrong_table")
+	err = runner.removeMigrationFromSchema(runner.store, *defaultMigrationList().head, "wrong_table")
 
 	if err == nil {
 		t.Errorf(`expected an error, but got %v`, err)
@@ -297,14 +511,109 @@ func TestRunnerRemoveMigrationFromSchema(t *testing.T) {
 	t.Cleanup(rebuildDatabaseSchema)
 }
 
+func TestRunnerApplyMigration(t *testing.T) {
+	runner := testRunner()
+
+	// Create schema table
+	testPostgresStore.Create(CreateMigrationTable(PostgreSQLDialect, runner.schemaTable))
+
+	// Scenario 1: Given a migration that fails part-way, none of its changes should be persisted
+	migration := Migration{
+		Version:  "20221231054540",
+		Engine:   "postgresql",
+		Name:     "CreateLikes",
+		FileName: "20221231054540_create_likes.yaml",
+		Changes: Changes{
+			Up:   []string{"CREATE TABLE likes (id SERIAL);", "CREATE TABLE likes (id SERIAL);"},
+			Down: []string{"DROP TABLE likes;"},
+		},
+	}
+
+	err := runner.applyMigration(migration)
+
+	if err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+
+	if IsTracked(testPostgresStore, "likes") {
+		t.Errorf(`expected table 'likes' to have been rolled back`)
+	}
+
+	if !IsEmpty(testPostgresStore, runner.schemaTable) {
+		t.Errorf(`expected migration not to have been registered`)
+	}
+
+	// Scenario 2: Given a valid migration, its changes and schema entry should be persisted
+	err = runner.applyMigration(*defaultMigrationList().head)
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	if IsEmpty(testPostgresStore, runner.schemaTable) {
+		t.Errorf(`expected migration to have been registered`)
+	}
+
+	// Scenario 3: Given a migration that opted out of transactions, changes before the failure are persisted
+	migration.DisableTransaction = true
+
+	err = runner.applyMigration(migration)
+
+	if err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+
+	if !IsTracked(testPostgresStore, "likes") {
+		t.Errorf(`expected table 'likes' to exist`)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerRevertMigration(t *testing.T) {
+	runner := testRunner()
+	migration := *defaultMigrationList().head
+
+	// Create schema table
+	testPostgresStore.Create(CreateMigrationTable(PostgreSQLDialect, runner.schemaTable))
+	runner.applyMigration(migration)
+
+	// Scenario 1: Given a rollback that fails, the schema entry should be kept
+	failing := migration
+	failing.Changes.Down = []string{"DROP TABLE users;", "DROP TABLE users;"}
+
+	err := runner.revertMigration(failing)
+
+	if err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+
+	if !IsTracked(testPostgresStore, "users") || IsEmpty(testPostgresStore, runner.schemaTable) {
+		t.Errorf(`expected rollback to have been reverted`)
+	}
+
+	// Scenario 2: Given a valid rollback, remove the table and the schema entry
+	err = runner.revertMigration(migration)
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	if IsTracked(testPostgresStore, "users") || !IsEmpty(testPostgresStore, runner.schemaTable) {
+		t.Errorf(`expected migration to have been reverted`)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
 func TestRunnerAppliedMigrations(t *testing.T) {
 	runner := testRunner()
-	directory := "./examples"
+	directories := []string{"./examples"}
 	filePattern := &FilePattern
 	loadFromDir := false
 
 	// Scenario 1: No applied migrations
-	migrations := runner.AppliedMigrations(directory, filePattern, loadFromDir)
+	migrations := runner.AppliedMigrations(directories, filePattern, loadFromDir)
 
 	if migrations.Size() != 0 {
 		t.Errorf(`expected no migrations to have been applied, but got %v`, migrations.Size())
@@ -316,12 +625,403 @@ func TestRunnerAppliedMigrations(t *testing.T) {
 	t.Cleanup(rebuildDatabaseSchema)
 }
 
+func TestRunnerAppliedMigrationsOrder(t *testing.T) {
+	runner := testRunner()
+	list := defaultMigrationList()
+	runner.Up(list)
+
+	first, second := *list.GetHead(), *list.GetHead().Next()
+
+	// NOTE: Updated rows can be moved by the database, and re-inserted rows get a later id
+	runner.store.Create(UpdateMigrationChecksum(DialectOf(runner.store), runner.schemaTable), "modified", second.Version)
+	runner.removeMigrationFromSchema(runner.store, first, runner.schemaTable)
+	runner.registerMigration(runner.store, first, runner.schemaTable)
+
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if versions, expected := applied.ToSlice().Versions(), list.ToSlice().Versions(); strings.Join(versions, ",") != strings.Join(expected, ",") {
+		t.Fatalf(`expected migrations ordered by version %v, but got %v`, expected, versions)
+	}
+
+	// NOTE: Rolling back one step reverts the most recent migration
+	applied.Reverse()
+
+	if last := applied.Take(1); last.GetHead().Version != list.GetTail().Version {
+		t.Errorf(`expected %v to be rolled back, but got %v`, list.GetTail().Version, last.GetHead().Version)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerBaseline(t *testing.T) {
+	runner := testRunner()
+	list := defaultMigrationList()
+	sequence, _ := list.Find("CreateArticles")
+
+	// Scenario 1: Migrations up to (and including) the target are recorded as applied
+	err := runner.Baseline(sequence)
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if applied.Size() != 2 || !applied.GetHead().Baselined || !applied.GetTail().Baselined {
+		t.Errorf(`expected 2 baselined migrations, but got %v`, applied.Description())
+	}
+
+	// NOTE: Baselined migrations are not run
+	if IsTracked(runner.store, "users") {
+		t.Errorf(`expected migration not to have been run`)
+	}
+
+	// Scenario 2: Applied migrations are skipped
+	err = runner.Baseline(defaultMigrationList())
+
+	if err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	applied = runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if applied.Size() != 3 {
+		t.Errorf(`expected 3 applied migrations, but got %v`, applied.Size())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerBatches(t *testing.T) {
+	runner := testRunner()
+	list := defaultMigrationList()
+
+	// Scenario 1: No batches
+	if runner.LastBatch() != 0 {
+		t.Errorf(`expected no batches, but got %v`, runner.LastBatch())
+	}
+
+	// Scenario 2: Each run of Up is a batch
+	first, _ := list.Find("CreateUsers")
+	runner.Up(first)
+	runner.Up(defaultMigrationList())
+
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if runner.LastBatch() != 2 || applied.GetHead().Batch != 1 || applied.GetTail().Batch != 2 {
+		t.Errorf(`expected 2 batches, but got %v`, applied.Description())
+	}
+
+	// Scenario 3: The last batch is rolled back
+	batch := applied.SinceBatch(runner.LastBatch())
+	batch.Reverse()
+	runner.Down(batch)
+
+	applied = runner.AppliedMigrations(nil, &FilePattern, false)
+
+	if applied.Size() != 1 || runner.LastBatch() != 1 {
+		t.Errorf(`expected 1 batch, but got %v`, applied.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerBatchRollbackOrder(t *testing.T) {
+	runner := testRunner()
+	list := defaultMigrationList()
+
+	first, _ := list.Find("CreateUsers")
+	runner.Up(first)
+	runner.Up(defaultMigrationList())
+
+	// NOTE: The row of CreateArticles (batch 2) is re-inserted after the row of CreateComments
+	articles, _ := list.Find("CreateArticles")
+	runner.removeMigrationFromSchema(runner.store, *articles.GetTail(), runner.schemaTable)
+	runner.registerMigration(runner.store, *articles.GetTail(), runner.schemaTable)
+
+	reverted := []string{}
+	runner.SetEventHandler(func(event Event) {
+		if event.Type == EventSucceeded {
+			reverted = append(reverted, event.Name)
+		}
+	})
+
+	applied := runner.AppliedMigrations(nil, &FilePattern, false)
+	batch := applied.SinceBatch(runner.LastBatch())
+	batch.Reverse()
+
+	if err := runner.Down(batch); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	// NOTE: Migrations of a batch are reverted in reverse version order
+	if strings.Join(reverted, ",") != "CreateComments,CreateArticles" {
+		t.Errorf(`expected CreateComments to be reverted before CreateArticles, but got %v`, reverted)
+	}
+
+	if applied = runner.AppliedMigrations(nil, &FilePattern, false); applied.Size() != 1 || applied.GetHead().Name != "CreateUsers" {
+		t.Errorf(`expected only the first batch to remain, but got %v`, applied.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerEvents(t *testing.T) {
+	runner := testRunner()
+	list := defaultMigrationList()
+
+	events := []Event{}
+	runner.SetEventHandler(func(event Event) { events = append(events, event) })
+
+	count := func(kind string) (n int) {
+		for _, event := range events {
+			if event.Type == kind {
+				n++
+			}
+		}
+
+		return n
+	}
+
+	// Scenario 1: Plans are not reported
+	runner.PlanUp(list)
+
+	if len(events) != 0 {
+		t.Errorf(`expected no events, but got %v`, events)
+	}
+
+	// Scenario 2: The run, each migration, and each statement are reported
+	runner.Up(list)
+
+	if count(EventStarted) != list.Size() || count(EventSucceeded) != list.Size() || count(EventStatement) == 0 {
+		t.Errorf(`expected every migration and statement to be reported, but got %v`, events)
+	}
+
+	if first, last := events[0], events[len(events)-1]; first.Type != EventRunStarted || last.Type != EventRunFinished || last.Completed != list.Size() {
+		t.Errorf(`expected a summary of the run, but got %v`, last)
+	}
+
+	if second := events[1]; second.Type != EventStarted || second.Version != list.GetHead().Version || second.Direction != "up" {
+		t.Errorf(`expected the migrations to be reported in order, but got %v`, second)
+	}
+
+	// Scenario 3: Rollbacks are reported as well
+	events = []Event{}
+	last := list.Take(list.Size())
+	last.Reverse()
+	last = last.Take(1)
+
+	runner.Down(last)
+
+	if count(EventSucceeded) != 1 || events[len(events)-1].Direction != "down" {
+		t.Errorf(`expected the rollback to be reported, but got %v`, events)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerRedo(t *testing.T) {
+	runner := testRunner()
+	directories := []string{"../examples"}
+
+	files := LoadMigrations(directories, &FilePattern)
+	list, _ := files.Find("CreateComments")
+
+	// Scenario 1: No applied migrations
+	if err := runner.Redo(1, directories, &FilePattern); err != nil {
+		t.Errorf(`expected no errors, but got %v`, err)
+	}
+
+	// Scenario 2: The last migrations are reverted and re-applied
+	runner.Up(list)
+
+	if err := runner.Redo(2, directories, &FilePattern); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	applied := runner.AppliedMigrations(directories, &FilePattern, false)
+	reverted, _ := runner.History(HistoryFilter{Action: HistoryRevert})
+
+	if applied.Size() != 3 || applied.GetTail().Name != "CreateComments" || applied.GetTail().Batch != 2 {
+		t.Errorf(`expected 3 applied migrations, but got %v`, applied.Description())
+	}
+
+	if len(reverted) != 2 || reverted[0].Name != "CreateArticles" {
+		t.Errorf(`expected 2 reverted migrations, but got %v`, reverted.Description())
+	}
+
+	// Scenario 3: The migration lock is held from the rollback to the migration
+	state := &lockState{}
+	runner.SetStore(lockRecorder{Store: runner.store, state: state})
+
+	if err := runner.Redo(2, directories, &FilePattern); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if state.acquisitions != 1 || state.held {
+		t.Errorf(`expected the lock to be acquired and released once, but got %v acquisition(s)`, state.acquisitions)
+	}
+
+	if len(state.unlocked) != 0 {
+		t.Errorf(`expected every change to be made while the lock is held, but got %v`, state.unlocked)
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+// lockState - How often a `lockRecorder` was locked, and the changes made while it was not.
+type lockState struct {
+	held         bool
+	acquisitions int
+	unlocked     []string
+}
+
+// lockRecorder - A store with a native lock that records how it is used. See `lockState`.
+type lockRecorder struct {
+	Store
+	state *lockState
+}
+
+func (r lockRecorder) Lock(key int64, timeout time.Duration) (func() error, error) {
+	if r.state.held {
+		return nil, errors.New("the lock is held")
+	}
+
+	r.state.held = true
+	r.state.acquisitions++
+
+	return func() error {
+		r.state.held = false
+		return nil
+	}, nil
+}
+
+func (r lockRecorder) Create(query string, options ...interface{}) error {
+	r.record(query)
+	return r.Store.Create(query, options...)
+}
+
+func (r lockRecorder) Delete(query string, options ...interface{}) error {
+	r.record(query)
+	return r.Store.Delete(query, options...)
+}
+
+func (r lockRecorder) Transaction(fn func(Store) error) error {
+	return r.Store.Transaction(func(tx Store) error {
+		return fn(lockRecorder{Store: tx, state: r.state})
+	})
+}
+
+func (r lockRecorder) record(query string) {
+	if !r.state.held {
+		r.state.unlocked = append(r.state.unlocked, query)
+	}
+}
+
 func TestRunnerPendingMigrations(t *testing.T) {
 	// TODO: Scenario 1: No migration file found
 	// TODO: Scenario 2: Unable to read migrations from the database
 	// TODO: Scenario 3: Pending migrations
 }
 
+func TestRunnerPendingMigrationsFromFS(t *testing.T) {
+	runner := testRunner()
+	runner.SetFS(defaultMigrationFS())
+
+	pending := runner.PendingMigrations([]string{"db/migrations"}, &FilePattern)
+
+	if pending.Size() != 2 || pending.GetHead().Name != "CreateUsers" {
+		t.Fatalf(`expected 2 pending migrations, but got %v`, pending.Description())
+	}
+
+	runner.Up(pending)
+
+	// NOTE: Applied migrations are compared with the files in the file system
+	if drifted := runner.DriftedMigrations([]string{"db/migrations"}, &FilePattern); len(drifted) != 0 {
+		t.Errorf(`expected no drifted migrations, but got %v`, drifted.Description())
+	}
+
+	if pending = runner.PendingMigrations([]string{"db/migrations"}, &FilePattern); pending.Size() != 0 {
+		t.Errorf(`expected no pending migrations, but got %v`, pending.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
+func TestRunnerUpRefusesDrift(t *testing.T) {
+	runner := testRunner()
+	files := defaultMigrationFS()
+	runner.SetFS(files)
+
+	directories := []string{"db/migrations"}
+	users := "db/migrations/20221231054530129328_create_users.yaml"
+
+	pending := runner.PendingMigrations(directories, &FilePattern)
+	runner.Up(pending.Take(1))
+
+	files[users] = &fstest.MapFile{
+		Data: []byte("engine: postgresql\nname: CreateUsers\nchanges:\n  up:\n    - CREATE TABLE users (id SERIAL, email VARCHAR);\n  down:\n    - DROP TABLE users;\n"),
+	}
+
+	// Scenario 1: Applied migrations that were modified are refused, along with the pending migrations
+	pending = runner.PendingMigrations(directories, &FilePattern)
+	err := runner.Up(pending)
+
+	var mismatch *ChecksumMismatchError
+
+	if !errors.As(err, &mismatch) || len(mismatch.Versions) != 1 || mismatch.Versions[0] != "20221231054530129328" {
+		t.Fatalf(`expected a checksum mismatch of CreateUsers, but got %v`, err)
+	}
+
+	if err = runner.Up(MigrationList{}); !errors.As(err, new(*ChecksumMismatchError)) {
+		t.Errorf(`expected a checksum mismatch when there is nothing to run, but got %v`, err)
+	}
+
+	if _, err = runner.PlanUp(pending); !errors.As(err, new(*ChecksumMismatchError)) {
+		t.Errorf(`expected the plan to be refused, but got %v`, err)
+	}
+
+	if err = runner.Check(pending); !errors.As(err, new(*ChecksumMismatchError)) {
+		t.Errorf(`expected the check to fail, but got %v`, err)
+	}
+
+	if pending = runner.PendingMigrations(directories, &FilePattern); pending.Size() != 1 {
+		t.Errorf(`expected no migrations to be applied, but got %v pending`, pending.Size())
+	}
+
+	// Scenario 2: Runners that did not load migrations compare the files they are given
+	other := testRunner()
+	list := BuildMigrationsFS(files, LoadFilesFS(files, &FilePattern), &FilePattern)
+
+	if err = other.Up(list); !errors.As(err, new(*ChecksumMismatchError)) {
+		t.Errorf(`expected a checksum mismatch, but got %v`, err)
+	}
+
+	// Scenario 3: Accepted drift is planned, then recorded along with the run
+	runner.SetAcceptDrift(true)
+
+	// NOTE: Checking does not record the accepted drift
+	if err = runner.Check(pending); err != nil || len(runner.DriftedMigrations(directories, &FilePattern)) != 1 {
+		t.Errorf(`expected the check to pass without recording the drift, but got %v`, err)
+	}
+
+	plan, err := runner.PlanUp(pending)
+
+	if err != nil || len(plan.Setup) == 0 || !strings.Contains(plan.Setup[len(plan.Setup)-1].Query, "checksum") {
+		t.Errorf(`expected the new checksum to be planned, but got %v (%v)`, plan.Setup, err)
+	}
+
+	if err = runner.Up(pending); err != nil {
+		t.Fatalf(`expected the migrations to be applied, but got %v`, err)
+	}
+
+	if drifted := runner.DriftedMigrations(directories, &FilePattern); len(drifted) != 0 {
+		t.Errorf(`expected the drift to be accepted, but got %v`, drifted.Description())
+	}
+
+	t.Cleanup(rebuildDatabaseSchema)
+}
+
 // TODO: Implement  tests
 func TestRunnerUp(t *testing.T) {}
 
@@ -1,9 +1,72 @@
 package migrations
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-func SchemaTableExists(table string) string {
-	return fmt.Sprintf(`SELECT 
+// Dialect - The flavor of SQL understood by a store.
+type Dialect string
+
+const (
+	PostgreSQLDialect Dialect = "postgresql"
+	SQLite3Dialect    Dialect = "sqlite3"
+	MySQLDialect      Dialect = "mysql"
+)
+
+// DialectOf - Returns the dialect of a store. Like a migration's engine, it is derived from the store's name.
+func DialectOf(store Store) Dialect {
+	return Dialect(strings.ToLower(store.Name()))
+}
+
+// Placeholders - Returns a comma-separated list of bind parameters for the given number of arguments.
+func (dialect Dialect) Placeholders(count int) string {
+	placeholders := []string{}
+
+	for position := 1; position <= count; position++ {
+		placeholders = append(placeholders, dialect.Placeholder(position))
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+// Placeholder - Returns the bind parameter for the argument at the given (1-based) position.
+func (dialect Dialect) Placeholder(position int) string {
+	switch dialect {
+	case MySQLDialect:
+		return "?"
+	case SQLite3Dialect:
+		return fmt.Sprintf("?%v", position)
+	default:
+		return fmt.Sprintf("$%v", position)
+	}
+}
+
+func SchemaTableExists(dialect Dialect, table string) string {
+	switch dialect {
+	case SQLite3Dialect:
+		return fmt.Sprintf(`SELECT 
+		'main' AS table_schema, 
+		name AS table_name,
+		'BASE TABLE' AS table_type
+		FROM 
+			sqlite_master 
+		WHERE 
+			type = 'table' AND
+			name = '%v';`, table)
+	case MySQLDialect:
+		return fmt.Sprintf(`SELECT 
+		TABLE_SCHEMA AS table_schema, 
+		TABLE_NAME AS table_name,
+		TABLE_TYPE AS table_type
+		FROM 
+			information_schema.TABLES 
+		WHERE 
+			TABLE_TYPE LIKE 'BASE TABLE' AND
+			TABLE_SCHEMA = DATABASE() AND
+			TABLE_NAME = '%v';`, table)
+	default:
+		return fmt.Sprintf(`SELECT 
 		TABLE_SCHEMA, 
 		TABLE_NAME,
 		TABLE_TYPE
@@ -12,43 +75,387 @@ func SchemaTableExists(table string) string {
 		WHERE 
 			TABLE_TYPE LIKE 'BASE TABLE' AND
 			TABLE_NAME = '%v';`, table)
+	}
 }
 
 func NumberOfAppliedMigrations(table string) string {
 	return fmt.Sprintf(`SELECT COUNT(id) FROM %v;`, table)
 }
 
-func CreateMigrationTable(table string) string {
-	return fmt.Sprintf(`CREATE TABLE %v (
+func CreateMigrationTable(dialect Dialect, table string) string {
+	switch dialect {
+	case SQLite3Dialect:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		version varchar UNIQUE NOT NULL,
+		name varchar UNIQUE NOT NULL,
+		checksum varchar(64),
+		baselined boolean NOT NULL DEFAULT FALSE,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		dirty boolean NOT NULL DEFAULT FALSE,
+		failed_statement integer,
+		batch integer,
+		created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`, table)
+	case MySQLDialect:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+		version varchar(255) UNIQUE NOT NULL,
+		name varchar(255) UNIQUE NOT NULL,
+		checksum varchar(64),
+		baselined boolean NOT NULL DEFAULT FALSE,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		dirty boolean NOT NULL DEFAULT FALSE,
+		failed_statement integer,
+		batch integer,
+		created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+		PRIMARY KEY(id)
+	);`, table)
+	default:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
 		id SERIAL,
 		version varchar UNIQUE NOT NULL,
 		name varchar UNIQUE NOT NULL,
+		checksum varchar(64),
+		baselined boolean NOT NULL DEFAULT FALSE,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		dirty boolean NOT NULL DEFAULT FALSE,
+		failed_statement integer,
+		batch integer,
 		created_at timestamp NOT NULL DEFAULT now(),
 
 		PRIMARY KEY(id)
 	);`, table)
+	}
+}
+
+// MigrationTableColumns - Columns added to the migration table after its first release.
+// Tables created by earlier versions of the tool are upgraded to include them. See `UpgradeTracking`.
+func MigrationTableColumns() []TableColumn {
+	return []TableColumn{
+		{Name: "checksum", Definition: "varchar(64)", Default: "''"},
+		{Name: "baselined", Definition: "boolean NOT NULL DEFAULT FALSE", Default: "FALSE"},
+		{Name: "duration_ms", Definition: "bigint", Default: "0"},
+		{Name: "applied_by", Definition: "varchar(255)", Default: "''"},
+		{Name: "host", Definition: "varchar(255)", Default: "''"},
+		{Name: "dm_version", Definition: "varchar(64)", Default: "''"},
+		{Name: "source", Definition: "varchar(16)", Default: "''"},
+		{Name: "dirty", Definition: "boolean NOT NULL DEFAULT FALSE", Default: "FALSE"},
+		{Name: "failed_statement", Definition: "integer", Default: "NULL"},
+		{Name: "batch", Definition: "integer", Default: "0"},
+	}
+}
+
+func SelectMigrationTableColumn(table string, column TableColumn) string {
+	return fmt.Sprintf("SELECT %v FROM %v WHERE 1 = 0;", column.Name, table)
+}
+
+func AddMigrationTableColumn(table string, column TableColumn) string {
+	return fmt.Sprintf("ALTER TABLE %v ADD COLUMN %v %v;", table, column.Name, column.Definition)
+}
+
+// LockTable - The table used to serialize migrations on stores without native locks.
+func LockTable(table string) string {
+	return fmt.Sprintf("%v_lock", table)
+}
+
+func CreateLockTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id INTEGER NOT NULL,
+		owner varchar(255) NOT NULL,
+		host varchar(255) NOT NULL,
+		pid integer NOT NULL,
+		acquired_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+		PRIMARY KEY(id)
+	);`, LockTable(table))
+}
+
+// CreateLockEntry - Acquires the lock, recording the owner, host, and process id of the runner holding it.
+func CreateLockEntry(dialect Dialect, table string) string {
+	return fmt.Sprintf("INSERT INTO %v (id, owner, host, pid) VALUES (1, %v);", LockTable(table), dialect.Placeholders(3))
+}
+
+// SelectLockEntry - The runner holding the lock. See `LockHolder`.
+func SelectLockEntry(table string) string {
+	return fmt.Sprintf("SELECT owner, host, pid, acquired_at FROM %v WHERE id = 1;", LockTable(table))
+}
+
+func DeleteLockEntry(table string) string {
+	return fmt.Sprintf("DELETE FROM %v WHERE id = 1;", LockTable(table))
 }
 
 func DropMigrationTable(table string) string {
 	return fmt.Sprintf("DROP TABLE %v;", table)
 }
 
-func SelectMigrations(table string) string {
-	return fmt.Sprintf("SELECT id, name, version FROM %v;", table)
+// executionColumns - Columns that describe how a migration was applied. They are NULL for migrations applied
+// before they were added to the schema table.
+var executionColumns = []string{
+	"COALESCE(duration_ms, 0) AS duration_ms",
+	"COALESCE(applied_by, '') AS applied_by",
+	"COALESCE(host, '') AS host",
+	"COALESCE(dm_version, '') AS dm_version",
+	"COALESCE(source, '') AS source",
+}
+
+// ExecutionColumns - Reads the columns that describe how a migration was applied.
+var ExecutionColumns = strings.Join(executionColumns, ", ")
+
+// SelectMigrations - The applied migrations, ordered by version. Rollbacks rely on this order, since rows are not returned in a guaranteed order (i.e. after an update).
+// Columns missing from the schema table are read as their defaults. See `MissingColumns`.
+func SelectMigrations(table string, missing ...TableColumn) string {
+	return fmt.Sprintf(
+		"SELECT id, name, version, %v, created_at, %v FROM %v ORDER BY version, id;",
+		withDefaults(missing, "COALESCE(checksum, '') AS checksum", "baselined", "dirty", "failed_statement", "COALESCE(batch, 0) AS batch"),
+		withDefaults(missing, executionColumns...),
+		table,
+	)
+}
+
+// SelectMigrationsVersion - The most recently applied migration. Columns missing from the schema table are read as their defaults.
+func SelectMigrationsVersion(table string, missing ...TableColumn) string {
+	return fmt.Sprintf("SELECT id, name, version, created_at, %v FROM %v ORDER BY id DESC LIMIT 1;", withDefaults(missing, executionColumns...), table)
 }
 
-func SelectMigrationsVersion(table string) string {
-	return fmt.Sprintf("SELECT id, name, version, created_at FROM %v ORDER BY id DESC LIMIT 1;", table)
+// withDefaults - Replaces the selected columns that are missing from the schema table with their defaults.
+func withDefaults(missing []TableColumn, selected ...string) string {
+	columns := []string{}
+
+	for _, expression := range selected {
+		name := expression[strings.LastIndex(expression, " ")+1:]
+
+		for _, column := range missing {
+			if column.Name == name {
+				expression = fmt.Sprintf("%v AS %v", column.Default, name)
+			}
+		}
+
+		columns = append(columns, expression)
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// SelectLastBatch - The most recent batch of migrations. Migrations applied before batches were recorded are in batch 0.
+func SelectLastBatch(table string) string {
+	return fmt.Sprintf("SELECT COALESCE(MAX(batch), 0) FROM %v;", table)
+}
+
+func CreateMigrationEntry(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"INSERT INTO %v (version, name, checksum, duration_ms, applied_by, host, dm_version, source, batch) VALUES (%v);",
+		table,
+		dialect.Placeholders(9),
+	)
+}
+
+// CreateBaselineEntry - Records a migration as applied without it having been run. See `Runner.Baseline`.
+func CreateBaselineEntry(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"INSERT INTO %v (version, name, checksum, duration_ms, applied_by, host, dm_version, source, baselined) VALUES (%v, TRUE);",
+		table,
+		dialect.Placeholders(8),
+	)
+}
+
+// CreateDirtyEntry - Records a migration whose changes were partially applied. See `Migration.Dirty`.
+func CreateDirtyEntry(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"INSERT INTO %v (version, name, checksum, duration_ms, applied_by, host, dm_version, source, batch, dirty, failed_statement) VALUES (%v, TRUE, %v);",
+		table,
+		dialect.Placeholders(9),
+		dialect.Placeholder(10),
+	)
+}
+
+func MarkMigrationDirty(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"UPDATE %v SET dirty = TRUE, failed_statement = %v WHERE version = %v;",
+		table,
+		dialect.Placeholder(1),
+		dialect.Placeholder(2),
+	)
+}
+
+// MarkMigrationClean - Clears the dirty state of a migration, recording the checksum of the changes it was repaired with.
+func MarkMigrationClean(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"UPDATE %v SET dirty = FALSE, failed_statement = NULL, checksum = %v WHERE version = %v;",
+		table,
+		dialect.Placeholder(1),
+		dialect.Placeholder(2),
+	)
+}
+
+func UpdateMigrationChecksum(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"UPDATE %v SET checksum = %v WHERE version = %v;",
+		table,
+		dialect.Placeholder(1),
+		dialect.Placeholder(2),
+	)
+}
+
+func DeleteMigrationEntry(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"DELETE FROM %v WHERE version = %v AND name = %v;",
+		table,
+		dialect.Placeholder(1),
+		dialect.Placeholder(2),
+	)
+}
+
+func SelectMigrationEntry(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"SELECT id, name, version FROM %v WHERE version = %v AND name = %v;",
+		table,
+		dialect.Placeholder(1),
+		dialect.Placeholder(2),
+	)
+}
+
+// MARK: - History
+
+// HistoryTable - The append-only table wherein every change to the schema table is recorded.
+func HistoryTable(table string) string {
+	return fmt.Sprintf("%v_history", table)
+}
+
+func CreateHistoryTable(dialect Dialect, table string) string {
+	switch dialect {
+	case SQLite3Dialect:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		version varchar NOT NULL,
+		name varchar NOT NULL,
+		action varchar(16) NOT NULL,
+		error_message text,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`, HistoryTable(table))
+	case MySQLDialect:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+		version varchar(255) NOT NULL,
+		name varchar(255) NOT NULL,
+		action varchar(16) NOT NULL,
+		error_message text,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+		PRIMARY KEY(id)
+	);`, HistoryTable(table))
+	default:
+		return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+		id SERIAL,
+		version varchar NOT NULL,
+		name varchar NOT NULL,
+		action varchar(16) NOT NULL,
+		error_message text,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		created_at timestamp NOT NULL DEFAULT now(),
+
+		PRIMARY KEY(id)
+	);`, HistoryTable(table))
+	}
+}
+
+func CreateHistoryEntry(dialect Dialect, table string) string {
+	return fmt.Sprintf(
+		"INSERT INTO %v (version, name, action, error_message, duration_ms, applied_by, host, dm_version, source) VALUES (%v);",
+		HistoryTable(table),
+		dialect.Placeholders(9),
+	)
+}
+
+// SelectHistoryEntries - Returns the query and arguments for the history entries that match the filter, most recent first.
+func SelectHistoryEntries(dialect Dialect, table string, filter HistoryFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Migration != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			"(version = %v OR name = %v)",
+			dialect.Placeholder(len(args)+1),
+			dialect.Placeholder(len(args)+2),
+		))
+		args = append(args, filter.Migration, filter.Migration)
+	}
+
+	if filter.Action != "" {
+		conditions = append(conditions, fmt.Sprintf("action = %v", dialect.Placeholder(len(args)+1)))
+		args = append(args, filter.Action)
+	}
+
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at >= %v", dialect.Placeholder(len(args)+1)))
+		args = append(args, filter.Since.UTC())
+	}
+
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("created_at <= %v", dialect.Placeholder(len(args)+1)))
+		args = append(args, filter.Until.UTC())
+	}
+
+	query := fmt.Sprintf(
+		"SELECT id, version, name, action, COALESCE(error_message, '') AS error_message, created_at, %v FROM %v",
+		ExecutionColumns,
+		HistoryTable(table),
+	)
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY id DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	return query + ";", args
+}
+
+// MARK: - Imported schema tables
+
+func SelectGolangMigrateVersion(table string) string {
+	return fmt.Sprintf("SELECT version, dirty FROM %v;", table)
 }
 
-func CreateMigrationEntry(table string) string {
-	return fmt.Sprintf("INSERT INTO %v (version, name) VALUES ($1, $2);", table)
+func SelectGooseVersions(table string) string {
+	return fmt.Sprintf("SELECT version_id, is_applied FROM %v ORDER BY id;", table)
 }
 
-func DeleteMigrationEntry(table string) string {
-	return fmt.Sprintf("DELETE FROM %v WHERE version = $1 AND name = $2;", table)
+func SelectFlywayVersions(table string) string {
+	return fmt.Sprintf("SELECT version, type, success FROM %v WHERE version IS NOT NULL ORDER BY installed_rank;", table)
 }
 
-func SelectMigrationEntry(table string) string {
-	return fmt.Sprintf("SELECT id, name, version FROM %v WHERE version = $1 AND name = $2;", table)
+func SelectRailsVersions(table string) string {
+	return fmt.Sprintf("SELECT version FROM %v;", table)
 }
@@ -0,0 +1,310 @@
+package migrations
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+/*
+SQL migration files:
+	Migrations can be written in plain SQL. Directives are written as comments prefixed with `-- +dm`:
+
+		-- +dm Engine postgresql
+		-- +dm NoTransaction
+
+		-- +dm Up
+		CREATE TABLE users (id SERIAL, name VARCHAR NOT NULL);
+
+		-- +dm Down
+		DROP TABLE users;
+
+	Statements are split on semicolons, ignoring those found in strings, quoted identifiers,
+	comments, and dollar-quoted bodies. Statements that cannot be split automatically can be
+	wrapped between `-- +dm StatementBegin` and `-- +dm StatementEnd`.
+*/
+
+const SQLDirectivePrefix = "-- +dm"
+
+var (
+	DollarQuotePattern = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+)
+
+// loadSQL - Populates the migration from the contents of a SQL migration file.
+func (instance *Migration) loadSQL(contents []byte) error {
+	return instance.parseSQL(contents, SQLDirectivePrefix)
+}
+
+// parseSQL - Populates the migration from annotated SQL whose directives start with `prefix`.
+func (instance *Migration) parseSQL(contents []byte, prefix string) error {
+	var section *[]string
+	var buffer strings.Builder
+	var block *strings.Builder
+
+	flush := func() {
+		if section != nil {
+			*section = append(*section, SplitStatements(instance.dialect(), buffer.String())...)
+		}
+
+		buffer.Reset()
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(contents)+1)
+
+	line := 0
+
+	for scanner.Scan() {
+		line += 1
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+
+		if !strings.HasPrefix(trimmed, prefix) {
+			if block != nil {
+				block.WriteString(text + "\n")
+				continue
+			}
+
+			if section == nil && hasCode(text) {
+				return InvalidSQLMigrationError{Line: line, Reason: fmt.Sprintf("statements must follow a '%v Up' or '%v Down' directive", prefix, prefix)}
+			}
+
+			buffer.WriteString(text + "\n")
+			continue
+		}
+
+		directive := strings.Fields(strings.TrimPrefix(trimmed, prefix))
+
+		if len(directive) == 0 {
+			return InvalidSQLMigrationError{Line: line, Reason: "empty directive"}
+		}
+
+		// NOTE: Some tools spell the directive as two words (i.e. `-- +goose NO TRANSACTION`)
+		if strings.EqualFold(strings.Join(directive, " "), "no transaction") {
+			directive = []string{"NoTransaction"}
+		}
+
+		switch strings.ToLower(directive[0]) {
+		case "up":
+			flush()
+			section = &instance.Changes.Up
+		case "down":
+			flush()
+			section = &instance.Changes.Down
+		case "engine":
+			if len(directive) != 2 {
+				return InvalidSQLMigrationError{Line: line, Reason: fmt.Sprintf("expected '%v Engine <name>'", prefix)}
+			}
+
+			instance.Engine = directive[1]
+		case "notransaction":
+			instance.DisableTransaction = true
+		case "statementbegin":
+			if section == nil || block != nil {
+				return InvalidSQLMigrationError{Line: line, Reason: fmt.Sprintf("unexpected '%v StatementBegin'", prefix)}
+			}
+
+			flush()
+			block = &strings.Builder{}
+		case "statementend":
+			if block == nil {
+				return InvalidSQLMigrationError{Line: line, Reason: fmt.Sprintf("unexpected '%v StatementEnd'", prefix)}
+			}
+
+			if statement := strings.TrimSpace(block.String()); statement != "" {
+				*section = append(*section, statement)
+			}
+
+			block = nil
+		default:
+			return InvalidSQLMigrationError{Line: line, Reason: fmt.Sprintf("unknown directive '%v'", directive[0])}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if block != nil {
+		return InvalidSQLMigrationError{Line: line, Reason: fmt.Sprintf("missing '%v StatementEnd'", prefix)}
+	}
+
+	flush()
+
+	return nil
+}
+
+// dialect - The dialect of SQL the migration is written in, derived from its engine.
+func (M Migration) dialect() Dialect {
+	return Dialect(strings.ToLower(M.Engine))
+}
+
+// SplitStatements - Splits SQL written in the given dialect into individual statements.
+// Semicolons inside strings, quoted identifiers, comments, and dollar-quoted bodies do not end a statement.
+func SplitStatements(dialect Dialect, sql string) []string {
+	statements := []string{}
+	runes := []rune(sql)
+
+	var current strings.Builder
+	code := false
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); code && statement != "" {
+			statements = append(statements, statement)
+		}
+
+		current.Reset()
+		code = false
+	}
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		next := rune(0)
+
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case c == '-' && next == '-':
+			current.WriteString("--")
+			i = copyUntil(&current, runes, i+2, "\n")
+		case c == '/' && next == '*':
+			current.WriteString("/*")
+			i = copyUntil(&current, runes, i+2, "*/")
+		case c == '\'' || c == '"' || c == '`':
+			code = true
+			// NOTE: MySQL escapes quotes with backslashes in every string. PostgreSQL does in escaped strings only (i.e. E'...').
+			escaped := (dialect == MySQLDialect && c != '`') || (c == '\'' && i > 0 && (runes[i-1] == 'E' || runes[i-1] == 'e'))
+			i = copyQuoted(&current, runes, i, escaped)
+		case c == '$' && (i == 0 || !isIdentifier(runes[i-1])) && DollarQuotePattern.MatchString(string(runes[i:])):
+			code = true
+			tag := DollarQuotePattern.FindString(string(runes[i:]))
+			current.WriteString(tag)
+			i = copyUntil(&current, runes, i+len([]rune(tag)), tag)
+		case c == ';':
+			current.WriteRune(c)
+			i += 1
+			flush()
+		default:
+			if !unicode.IsSpace(c) {
+				code = true
+			}
+
+			current.WriteRune(c)
+			i += 1
+		}
+	}
+
+	flush()
+
+	return statements
+}
+
+// normalizeStatement - Collapses whitespace outside of strings, quoted identifiers, comments, and dollar-quoted bodies,
+// so that reformatting a statement does not change it, while changing the contents of a string does.
+func normalizeStatement(statement string) string {
+	runes := []rune(strings.TrimSpace(strings.ReplaceAll(statement, "\r\n", "\n")))
+
+	var normalized strings.Builder
+	space := false
+
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		next := rune(0)
+
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		if unicode.IsSpace(c) {
+			space = true
+			i += 1
+			continue
+		}
+
+		if space {
+			normalized.WriteRune(' ')
+			space = false
+		}
+
+		switch {
+		case c == '-' && next == '-':
+			normalized.WriteString("--")
+			i = copyUntil(&normalized, runes, i+2, "\n")
+			space = true
+		case c == '/' && next == '*':
+			normalized.WriteString("/*")
+			i = copyUntil(&normalized, runes, i+2, "*/")
+		case c == '\'' || c == '"' || c == '`':
+			escaped := c == '\'' && i > 0 && (runes[i-1] == 'E' || runes[i-1] == 'e')
+			i = copyQuoted(&normalized, runes, i, escaped)
+		case c == '$' && (i == 0 || !isIdentifier(runes[i-1])) && DollarQuotePattern.MatchString(string(runes[i:])):
+			tag := DollarQuotePattern.FindString(string(runes[i:]))
+			normalized.WriteString(tag)
+			i = copyUntil(&normalized, runes, i+len([]rune(tag)), tag)
+		default:
+			normalized.WriteRune(c)
+			i += 1
+		}
+	}
+
+	return strings.TrimRight(normalized.String(), "\n")
+}
+
+// copyUntil - Copies runes up to and including the first occurrence of `terminator` at or after `from`.
+func copyUntil(current *strings.Builder, runes []rune, from int, terminator string) int {
+	length := len([]rune(terminator))
+
+	for i := from; i+length <= len(runes); i++ {
+		if string(runes[i:i+length]) == terminator {
+			current.WriteString(string(runes[from : i+length]))
+			return i + length
+		}
+	}
+
+	current.WriteString(string(runes[from:]))
+	return len(runes)
+}
+
+// copyQuoted - Copies a quoted string or identifier starting at `from`. Doubled quotes are treated as escaped quotes.
+// Backslashes escape quotes only in escaped strings (see `SplitStatements`).
+func copyQuoted(current *strings.Builder, runes []rune, from int, escaped bool) int {
+	quote := runes[from]
+	current.WriteRune(quote)
+
+	for i := from + 1; i < len(runes); i++ {
+		current.WriteRune(runes[i])
+
+		if escaped && runes[i] == '\\' && i+1 < len(runes) {
+			i += 1
+			current.WriteRune(runes[i])
+			continue
+		}
+
+		if runes[i] == quote {
+			if i+1 < len(runes) && runes[i+1] == quote {
+				i += 1
+				current.WriteRune(runes[i])
+				continue
+			}
+
+			return i + 1
+		}
+	}
+
+	return len(runes)
+}
+
+func isIdentifier(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// hasCode - Indicates whether a line contains anything other than whitespace and comments.
+func hasCode(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !strings.HasPrefix(trimmed, "--")
+}
@@ -0,0 +1,203 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	scenarios := []struct {
+		dialect    Dialect
+		sql        string
+		statements []string
+	}{
+		// Scenario 1: Plain statements
+		{
+			sql:        "CREATE TABLE users (id SERIAL);\nCREATE TABLE articles (id SERIAL);\n",
+			statements: []string{"CREATE TABLE users (id SERIAL);", "CREATE TABLE articles (id SERIAL);"},
+		},
+		// Scenario 2: Semicolons in strings and quoted identifiers
+		{
+			sql:        `INSERT INTO notes (body) VALUES ('a; b'), ('it''s; here'); SELECT "weird;name" FROM notes;`,
+			statements: []string{`INSERT INTO notes (body) VALUES ('a; b'), ('it''s; here');`, `SELECT "weird;name" FROM notes;`},
+		},
+		// Scenario 3: Escaped strings
+		{
+			dialect:    PostgreSQLDialect,
+			sql:        `INSERT INTO notes (body) VALUES (E'it\'s; here'); SELECT 1;`,
+			statements: []string{`INSERT INTO notes (body) VALUES (E'it\'s; here');`, `SELECT 1;`},
+		},
+		// Scenario 4: Dollar-quoted function bodies
+		{
+			sql: "CREATE FUNCTION one() RETURNS int AS $body$\nBEGIN\n  RETURN 1;\nEND;\n$body$ LANGUAGE plpgsql;\nCREATE FUNCTION two() RETURNS int AS $$ SELECT 2; $$ LANGUAGE sql;",
+			statements: []string{
+				"CREATE FUNCTION one() RETURNS int AS $body$\nBEGIN\n  RETURN 1;\nEND;\n$body$ LANGUAGE plpgsql;",
+				"CREATE FUNCTION two() RETURNS int AS $$ SELECT 2; $$ LANGUAGE sql;",
+			},
+		},
+		// Scenario 5: Comments
+		{
+			sql:        "-- drop; everything\n/* not; yet */\nDROP TABLE users;\n-- trailing comment;\n",
+			statements: []string{"-- drop; everything\n/* not; yet */\nDROP TABLE users;"},
+		},
+		// Scenario 6: Positional parameters are not dollar quotes
+		{
+			sql:        "PREPARE find (int) AS SELECT * FROM users WHERE id = $1; EXECUTE find(1);",
+			statements: []string{"PREPARE find (int) AS SELECT * FROM users WHERE id = $1;", "EXECUTE find(1);"},
+		},
+		// Scenario 7: A final statement without a semicolon
+		{
+			sql:        "DROP TABLE users",
+			statements: []string{"DROP TABLE users"},
+		},
+		// Scenario 8: MySQL escapes quotes with backslashes in every string
+		{
+			dialect:    MySQLDialect,
+			sql:        `INSERT INTO notes (body) VALUES ('it\'s; fine'), ("say \"hi\"; now"); SELECT 1;`,
+			statements: []string{`INSERT INTO notes (body) VALUES ('it\'s; fine'), ("say \"hi\"; now");`, `SELECT 1;`},
+		},
+		// Scenario 9: Backslashes are not escapes in standard PostgreSQL strings
+		{
+			dialect:    PostgreSQLDialect,
+			sql:        `INSERT INTO paths (path) VALUES ('C:\'); SELECT 1;`,
+			statements: []string{`INSERT INTO paths (path) VALUES ('C:\');`, `SELECT 1;`},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		statements := SplitStatements(scenario.dialect, scenario.sql)
+
+		if len(statements) != len(scenario.statements) {
+			t.Fatalf(`scenario %v: expected %v statements, but got %v: %q`, index+1, len(scenario.statements), len(statements), statements)
+		}
+
+		for i, statement := range statements {
+			if statement != scenario.statements[i] {
+				t.Errorf(`scenario %v: expected %q, but got %q`, index+1, scenario.statements[i], statement)
+			}
+		}
+	}
+}
+
+func TestNormalizeStatement(t *testing.T) {
+	scenarios := []struct {
+		statement  string
+		normalized string
+	}{
+		{"  CREATE TABLE likes (id SERIAL,\n\tcontent_id INT);\n", "CREATE TABLE likes (id SERIAL, content_id INT);"},
+		{"INSERT INTO notes (body) VALUES ('a  b'), (E'it\\'s  here');", "INSERT INTO notes (body) VALUES ('a  b'), (E'it\\'s  here');"},
+		{`SELECT "odd  name" FROM notes;`, `SELECT "odd  name" FROM notes;`},
+		{"-- don't  split\nSELECT   1;", "-- don't  split\n SELECT 1;"},
+		{"CREATE FUNCTION one() RETURNS int AS $$  SELECT   1;  $$ LANGUAGE sql;", "CREATE FUNCTION one() RETURNS int AS $$  SELECT   1;  $$ LANGUAGE sql;"},
+	}
+
+	for _, scenario := range scenarios {
+		if normalized := normalizeStatement(scenario.statement); normalized != scenario.normalized {
+			t.Errorf(`expected %q, but got %q`, scenario.normalized, normalized)
+		}
+	}
+}
+
+func TestMigrationLoadSQL(t *testing.T) {
+	contents := `-- +dm Engine postgresql
+-- +dm NoTransaction
+
+-- +dm Up
+CREATE TABLE users (id SERIAL, name VARCHAR NOT NULL);
+CREATE INDEX CONCURRENTLY users_name_idx ON users (name);
+
+-- +dm StatementBegin
+CREATE FUNCTION users_count() RETURNS bigint AS 'SELECT count(*) FROM users;' LANGUAGE sql;
+-- +dm StatementEnd
+
+-- +dm Down
+DROP FUNCTION users_count();
+DROP TABLE users;
+`
+
+	var migration Migration
+
+	err := migration.loadSQL([]byte(contents))
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if migration.Engine != "postgresql" || !migration.DisableTransaction {
+		t.Errorf(`expected engine and transaction directives to be applied, but got (%v, %v)`, migration.Engine, migration.DisableTransaction)
+	}
+
+	if len(migration.Changes.Up) != 3 || len(migration.Changes.Down) != 2 {
+		t.Errorf(`expected 3 up and 2 down changes, but got %q and %q`, migration.Changes.Up, migration.Changes.Down)
+	}
+}
+
+func TestMigrationLoadSQLForMySQL(t *testing.T) {
+	contents := `-- +dm Engine mysql
+
+-- +dm Up
+INSERT INTO notes (body) VALUES ('it\'s; fine');
+
+-- +dm Down
+DELETE FROM notes;
+`
+
+	var migration Migration
+
+	if err := migration.loadSQL([]byte(contents)); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if len(migration.Changes.Up) != 1 {
+		t.Errorf(`expected 1 up change, but got %q`, migration.Changes.Up)
+	}
+}
+
+func TestMigrationLoadInvalidSQL(t *testing.T) {
+	scenarios := []string{
+		// Scenario 1: Statements before a section directive
+		"CREATE TABLE users (id SERIAL);\n-- +dm Up\n",
+		// Scenario 2: Unknown directive
+		"-- +dm Sideways\n",
+		// Scenario 3: Unterminated statement block
+		"-- +dm Up\n-- +dm StatementBegin\nSELECT 1;\n",
+		// Scenario 4: Statement block outside of a section
+		"-- +dm StatementBegin\nSELECT 1;\n-- +dm StatementEnd\n",
+	}
+
+	for index, contents := range scenarios {
+		var migration Migration
+
+		err := migration.loadSQL([]byte(contents))
+
+		if !errors.As(err, new(InvalidSQLMigrationError)) {
+			t.Errorf(`scenario %v: expected InvalidSQLMigrationError, but got %v`, index+1, err)
+		}
+	}
+}
+
+func TestBuildMigrationsFromSQLFile(t *testing.T) {
+	files := LoadFiles("../examples", &FilePattern)
+	list := BuildMigrations(files, "../examples", &FilePattern)
+
+	migration, found := list.ToMap()["20220504202521118230"]
+
+	if !found {
+		t.Fatalf(`expected sql migration to have been loaded`)
+	}
+
+	if migration.Name != "AddArticleSlugs" || migration.Engine != "postgresql" {
+		t.Errorf(`expected AddArticleSlugs (postgresql), but got %v (%v)`, migration.Name, migration.Engine)
+	}
+
+	if len(migration.Changes.Up) != 3 || len(migration.Changes.Down) != 3 {
+		t.Errorf(`expected 3 up and 3 down changes, but got %v and %v`, len(migration.Changes.Up), len(migration.Changes.Down))
+	}
+
+	sequence, _ := list.Find("20220504202521118230")
+	valid, reason := Validate(sequence)
+
+	if !valid {
+		t.Errorf(`expected sql migration to be valid, but got %v`, reason)
+	}
+}
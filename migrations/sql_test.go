@@ -2,12 +2,15 @@ package migrations
 
 import (
 	"testing"
+	"time"
+
+	"github.com/oleoneto/dm/stores"
 )
 
 func TestSchemaTableExists(t *testing.T) {
 	table := "schema_migrations"
 
-	query := SchemaTableExists(table)
+	query := SchemaTableExists(PostgreSQLDialect, table)
 	formatted := `SELECT 
 		TABLE_SCHEMA, 
 		TABLE_NAME,
@@ -23,6 +26,45 @@ func TestSchemaTableExists(t *testing.T) {
 	}
 }
 
+func TestSQLite3SchemaTableExists(t *testing.T) {
+	table := "schema_migrations"
+
+	query := SchemaTableExists(SQLite3Dialect, table)
+	formatted := `SELECT 
+		'main' AS table_schema, 
+		name AS table_name,
+		'BASE TABLE' AS table_type
+		FROM 
+			sqlite_master 
+		WHERE 
+			type = 'table' AND
+			name = 'schema_migrations';`
+
+	if query != formatted {
+		t.Fatalf(`got incorrect query %v`, query)
+	}
+}
+
+func TestMySQLSchemaTableExists(t *testing.T) {
+	table := "schema_migrations"
+
+	query := SchemaTableExists(MySQLDialect, table)
+	formatted := `SELECT 
+		TABLE_SCHEMA AS table_schema, 
+		TABLE_NAME AS table_name,
+		TABLE_TYPE AS table_type
+		FROM 
+			information_schema.TABLES 
+		WHERE 
+			TABLE_TYPE LIKE 'BASE TABLE' AND
+			TABLE_SCHEMA = DATABASE() AND
+			TABLE_NAME = 'schema_migrations';`
+
+	if query != formatted {
+		t.Fatalf(`got incorrect query %v`, query)
+	}
+}
+
 func TestNumberOfAppliedMigrations(t *testing.T) {
 	table := "schema_migrations"
 
@@ -37,11 +79,21 @@ func TestNumberOfAppliedMigrations(t *testing.T) {
 func TestCreateMigrationTable(t *testing.T) {
 	table := "schema_migrations"
 
-	query := CreateMigrationTable(table)
-	formatted := `CREATE TABLE schema_migrations (
+	query := CreateMigrationTable(PostgreSQLDialect, table)
+	formatted := `CREATE TABLE IF NOT EXISTS schema_migrations (
 		id SERIAL,
 		version varchar UNIQUE NOT NULL,
 		name varchar UNIQUE NOT NULL,
+		checksum varchar(64),
+		baselined boolean NOT NULL DEFAULT FALSE,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		dirty boolean NOT NULL DEFAULT FALSE,
+		failed_statement integer,
+		batch integer,
 		created_at timestamp NOT NULL DEFAULT now(),
 
 		PRIMARY KEY(id)
@@ -52,6 +104,91 @@ func TestCreateMigrationTable(t *testing.T) {
 	}
 }
 
+func TestSQLite3CreateMigrationTable(t *testing.T) {
+	table := "schema_migrations"
+
+	query := CreateMigrationTable(SQLite3Dialect, table)
+	formatted := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		version varchar UNIQUE NOT NULL,
+		name varchar UNIQUE NOT NULL,
+		checksum varchar(64),
+		baselined boolean NOT NULL DEFAULT FALSE,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		dirty boolean NOT NULL DEFAULT FALSE,
+		failed_statement integer,
+		batch integer,
+		created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`
+
+	if query != formatted {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestMySQLCreateMigrationTable(t *testing.T) {
+	table := "schema_migrations"
+
+	query := CreateMigrationTable(MySQLDialect, table)
+	formatted := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+		version varchar(255) UNIQUE NOT NULL,
+		name varchar(255) UNIQUE NOT NULL,
+		checksum varchar(64),
+		baselined boolean NOT NULL DEFAULT FALSE,
+		duration_ms bigint,
+		applied_by varchar(255),
+		host varchar(255),
+		dm_version varchar(64),
+		source varchar(16),
+		dirty boolean NOT NULL DEFAULT FALSE,
+		failed_statement integer,
+		batch integer,
+		created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+		PRIMARY KEY(id)
+	);`
+
+	if query != formatted {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestLockTable(t *testing.T) {
+	table := "schema_migrations"
+
+	query := CreateLockTable(table)
+	formatted := `CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+		id INTEGER NOT NULL,
+		owner varchar(255) NOT NULL,
+		host varchar(255) NOT NULL,
+		pid integer NOT NULL,
+		acquired_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+		PRIMARY KEY(id)
+	);`
+
+	if query != formatted {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	if query = CreateLockEntry(MySQLDialect, table); query != `INSERT INTO schema_migrations_lock (id, owner, host, pid) VALUES (1, ?, ?, ?);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	if query = SelectLockEntry(table); query != `SELECT owner, host, pid, acquired_at FROM schema_migrations_lock WHERE id = 1;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	if query = DeleteLockEntry(table); query != `DELETE FROM schema_migrations_lock WHERE id = 1;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
 func TestDropMigrationTable(t *testing.T) {
 	table := "schema_migrations"
 
@@ -67,7 +204,7 @@ func TestSelectMigrations(t *testing.T) {
 
 	query := SelectMigrations(table)
 
-	if query != `SELECT id, name, version FROM schema_migrations;` {
+	if query != `SELECT id, name, version, COALESCE(checksum, '') AS checksum, baselined, dirty, failed_statement, COALESCE(batch, 0) AS batch, created_at, COALESCE(duration_ms, 0) AS duration_ms, COALESCE(applied_by, '') AS applied_by, COALESCE(host, '') AS host, COALESCE(dm_version, '') AS dm_version, COALESCE(source, '') AS source FROM schema_migrations ORDER BY version, id;` {
 		t.Fatalf(`got incorrect %v`, query)
 	}
 }
@@ -77,7 +214,7 @@ func TestSelectMigrationsVersion(t *testing.T) {
 
 	query := SelectMigrationsVersion(table)
 
-	if query != `SELECT id, name, version, created_at FROM schema_migrations ORDER BY id DESC LIMIT 1;` {
+	if query != `SELECT id, name, version, created_at, COALESCE(duration_ms, 0) AS duration_ms, COALESCE(applied_by, '') AS applied_by, COALESCE(host, '') AS host, COALESCE(dm_version, '') AS dm_version, COALESCE(source, '') AS source FROM schema_migrations ORDER BY id DESC LIMIT 1;` {
 		t.Fatalf(`got incorrect %v`, query)
 	}
 }
@@ -85,9 +222,82 @@ func TestSelectMigrationsVersion(t *testing.T) {
 func TestCreateMigrationEntry(t *testing.T) {
 	table := "schema_migrations"
 
-	query := CreateMigrationEntry(table)
+	query := CreateMigrationEntry(PostgreSQLDialect, table)
+
+	if query != `INSERT INTO schema_migrations (version, name, checksum, duration_ms, applied_by, host, dm_version, source, batch) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestSelectLastBatch(t *testing.T) {
+	query := SelectLastBatch("schema_migrations")
+
+	if query != `SELECT COALESCE(MAX(batch), 0) FROM schema_migrations;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestCreateBaselineEntry(t *testing.T) {
+	table := "schema_migrations"
+
+	query := CreateBaselineEntry(PostgreSQLDialect, table)
+
+	if query != `INSERT INTO schema_migrations (version, name, checksum, duration_ms, applied_by, host, dm_version, source, baselined) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, TRUE);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	query = CreateBaselineEntry(MySQLDialect, table)
 
-	if query != `INSERT INTO schema_migrations (version, name) VALUES ($1, $2);` {
+	if query != `INSERT INTO schema_migrations (version, name, checksum, duration_ms, applied_by, host, dm_version, source, baselined) VALUES (?, ?, ?, ?, ?, ?, ?, ?, TRUE);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestDirtyMigrationEntries(t *testing.T) {
+	table := "schema_migrations"
+
+	query := CreateDirtyEntry(PostgreSQLDialect, table)
+
+	if query != `INSERT INTO schema_migrations (version, name, checksum, duration_ms, applied_by, host, dm_version, source, batch, dirty, failed_statement) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, TRUE, $10);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	query = MarkMigrationDirty(SQLite3Dialect, table)
+
+	if query != `UPDATE schema_migrations SET dirty = TRUE, failed_statement = ?1 WHERE version = ?2;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	query = MarkMigrationClean(MySQLDialect, table)
+
+	if query != `UPDATE schema_migrations SET dirty = FALSE, failed_statement = NULL, checksum = ? WHERE version = ?;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestUpdateMigrationChecksum(t *testing.T) {
+	table := "schema_migrations"
+
+	query := UpdateMigrationChecksum(PostgreSQLDialect, table)
+
+	if query != `UPDATE schema_migrations SET checksum = $1 WHERE version = $2;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestMigrationTableColumnUpgrades(t *testing.T) {
+	table := "schema_migrations"
+	column := TableColumn{Name: "checksum", Definition: "varchar(64)"}
+
+	query := SelectMigrationTableColumn(table, column)
+
+	if query != `SELECT checksum FROM schema_migrations WHERE 1 = 0;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	query = AddMigrationTableColumn(table, column)
+
+	if query != `ALTER TABLE schema_migrations ADD COLUMN checksum varchar(64);` {
 		t.Fatalf(`got incorrect %v`, query)
 	}
 }
@@ -95,7 +305,7 @@ func TestCreateMigrationEntry(t *testing.T) {
 func TestDeleteMigrationEntry(t *testing.T) {
 	table := "schema_migrations"
 
-	query := DeleteMigrationEntry(table)
+	query := DeleteMigrationEntry(PostgreSQLDialect, table)
 
 	if query != `DELETE FROM schema_migrations WHERE version = $1 AND name = $2;` {
 		t.Fatalf(`got incorrect %v`, query)
@@ -106,9 +316,110 @@ func TestDeleteMigrationEntry(t *testing.T) {
 func TestSelectMigrationEntry(t *testing.T) {
 	table := "schema_migrations"
 
-	query := SelectMigrationEntry(table)
+	query := SelectMigrationEntry(PostgreSQLDialect, table)
 
 	if query != `SELECT id, name, version FROM schema_migrations WHERE version = $1 AND name = $2;` {
 		t.Fatalf(`got incorrect %v`, query)
 	}
 }
+
+func TestSQLite3MigrationEntry(t *testing.T) {
+	table := "schema_migrations"
+
+	query := CreateMigrationEntry(SQLite3Dialect, table)
+
+	if query != `INSERT INTO schema_migrations (version, name, checksum, duration_ms, applied_by, host, dm_version, source, batch) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	query = DeleteMigrationEntry(SQLite3Dialect, table)
+
+	if query != `DELETE FROM schema_migrations WHERE version = ?1 AND name = ?2;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestMySQLMigrationEntry(t *testing.T) {
+	table := "schema_migrations"
+
+	query := CreateMigrationEntry(MySQLDialect, table)
+
+	if query != `INSERT INTO schema_migrations (version, name, checksum, duration_ms, applied_by, host, dm_version, source, batch) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	query = DeleteMigrationEntry(MySQLDialect, table)
+
+	if query != `DELETE FROM schema_migrations WHERE version = ? AND name = ?;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestHistoryEntry(t *testing.T) {
+	table := "schema_migrations"
+
+	if HistoryTable(table) != "schema_migrations_history" {
+		t.Fatalf(`got incorrect %v`, HistoryTable(table))
+	}
+
+	query := CreateHistoryEntry(PostgreSQLDialect, table)
+
+	if query != `INSERT INTO schema_migrations_history (version, name, action, error_message, duration_ms, applied_by, host, dm_version, source) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	query = CreateHistoryEntry(MySQLDialect, table)
+
+	if query != `INSERT INTO schema_migrations_history (version, name, action, error_message, duration_ms, applied_by, host, dm_version, source) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestSelectHistoryEntries(t *testing.T) {
+	table := "schema_migrations"
+	columns := "SELECT id, version, name, action, COALESCE(error_message, '') AS error_message, created_at, " + ExecutionColumns
+
+	// Scenario 1: No filters
+	query, args := SelectHistoryEntries(PostgreSQLDialect, table, HistoryFilter{})
+
+	if query != columns+` FROM schema_migrations_history ORDER BY id DESC;` || len(args) != 0 {
+		t.Fatalf(`got incorrect %v (%v)`, query, args)
+	}
+
+	// Scenario 2: All filters
+	since := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2022, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	filter := HistoryFilter{Migration: "CreateUsers", Action: HistoryRevert, Since: since, Until: until, Limit: 10}
+
+	query, args = SelectHistoryEntries(PostgreSQLDialect, table, filter)
+
+	if query != columns+` FROM schema_migrations_history WHERE (version = $1 OR name = $2) AND action = $3 AND created_at >= $4 AND created_at <= $5 ORDER BY id DESC LIMIT 10;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+
+	if len(args) != 5 || args[0] != "CreateUsers" || args[2] != HistoryRevert || args[3] != since || args[4] != until {
+		t.Fatalf(`got incorrect arguments %v`, args)
+	}
+
+	// Scenario 3: Positional placeholders
+	query, _ = SelectHistoryEntries(MySQLDialect, table, HistoryFilter{Action: HistoryApply})
+
+	if query != columns+` FROM schema_migrations_history WHERE action = ? ORDER BY id DESC;` {
+		t.Fatalf(`got incorrect %v`, query)
+	}
+}
+
+func TestDialectOf(t *testing.T) {
+	if dialect := DialectOf(testPostgresStore); dialect != PostgreSQLDialect {
+		t.Fatalf(`expected %v, but got %v`, PostgreSQLDialect, dialect)
+	}
+
+	if dialect := DialectOf(stores.SQLite3{}); dialect != SQLite3Dialect {
+		t.Fatalf(`expected %v, but got %v`, SQLite3Dialect, dialect)
+	}
+
+	if dialect := DialectOf(stores.MySQL{}); dialect != MySQLDialect {
+		t.Fatalf(`expected %v, but got %v`, MySQLDialect, dialect)
+	}
+}
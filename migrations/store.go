@@ -1,5 +1,11 @@
 package migrations
 
+import (
+	"time"
+
+	"github.com/oleoneto/dm/stores"
+)
+
 type DatabaseConnector interface {
 	// Connect - Acquire a connection to the database.
 	Connect() error
@@ -8,18 +14,13 @@ type DatabaseConnector interface {
 	Disconnect() error
 }
 
-type Store interface {
-	// Create - Adds record(s) to the store.
-	Create(string, ...interface{}) error
-
-	// Read - Reads record from the store.
-	Read(string, interface{}, ...interface{}) error
-
-	// Delete - Removes record(s) from the store.
-	Delete(string, ...interface{}) error
-
-	// Name - A string that identifies this store.
-	Name() string
+// Store - Any type that can create, read, and delete records. See `stores.Store`.
+type Store = stores.Store
 
-	DatabaseURL() string
+// Locker - Implemented by stores that support database-native (advisory) locks.
+// Stores that do not implement it are locked through a lock table. See `Runner.Up()`.
+type Locker interface {
+	// Lock - Blocks until the lock identified by key is acquired or the timeout elapses.
+	// The returned function releases the lock.
+	Lock(key int64, timeout time.Duration) (func() error, error)
 }
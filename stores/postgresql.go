@@ -2,8 +2,11 @@ package stores
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -12,6 +15,13 @@ type Postgres struct {
 	URL      string
 }
 
+// PostgresTransaction - A store bound to an open transaction.
+// Obtained by calling `Postgres.Transaction()`.
+type PostgresTransaction struct {
+	tx  pgx.Tx
+	URL string
+}
+
 func (store *Postgres) Connect() error {
 	conn, err := pgxpool.Connect(context.Background(), store.URL)
 
@@ -64,3 +74,113 @@ func (store Postgres) Delete(query string, options ...interface{}) error {
 	_, err := store.instance.Exec(context.Background(), query, options...)
 	return err
 }
+
+func (store Postgres) Transaction(fn func(Store) error) error {
+	err := store.Connect()
+
+	if err != nil {
+		return err
+	}
+
+	defer store.Disconnect()
+
+	tx, err := store.instance.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return runPostgresTransaction(PostgresTransaction{tx: tx, URL: store.URL}, tx, fn)
+}
+
+// Lock - Acquires a session-level advisory lock on a dedicated connection.
+// The lock is released when the returned function is called or the connection is closed.
+func (store Postgres) Lock(key int64, timeout time.Duration) (func() error, error) {
+	conn, err := pgx.Connect(context.Background(), store.URL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	release := func() error {
+		_, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1);", key)
+		conn.Close(context.Background())
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+
+	for {
+		var acquired bool
+
+		err = conn.QueryRow(context.Background(), "SELECT pg_try_advisory_lock($1);", key).Scan(&acquired)
+
+		if err != nil {
+			conn.Close(context.Background())
+			return nil, err
+		}
+
+		if acquired {
+			return release, nil
+		}
+
+		if time.Now().After(deadline) {
+			conn.Close(context.Background())
+			return nil, fmt.Errorf("timed out after %v waiting for advisory lock", timeout)
+		}
+
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// MARK: - Transaction-bound store
+
+func (store PostgresTransaction) Name() string {
+	return "PostgreSQL"
+}
+
+func (store PostgresTransaction) DatabaseURL() string {
+	return store.URL
+}
+
+func (store PostgresTransaction) Create(query string, options ...interface{}) error {
+	_, err := store.tx.Exec(context.Background(), query, options...)
+	return err
+}
+
+func (store PostgresTransaction) Read(query string, model interface{}, options ...interface{}) error {
+	rows, err := store.tx.Query(context.Background(), query, options...)
+
+	if err != nil {
+		return err
+	}
+
+	return pgxscan.ScanAll(model, rows)
+}
+
+func (store PostgresTransaction) Delete(query string, options ...interface{}) error {
+	_, err := store.tx.Exec(context.Background(), query, options...)
+	return err
+}
+
+// Transaction - Nested transactions are implemented with savepoints.
+func (store PostgresTransaction) Transaction(fn func(Store) error) error {
+	tx, err := store.tx.Begin(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return runPostgresTransaction(PostgresTransaction{tx: tx, URL: store.URL}, tx, fn)
+}
+
+func runPostgresTransaction(store Store, tx pgx.Tx, fn func(Store) error) error {
+	err := fn(store)
+
+	if err != nil {
+		_ = tx.Rollback(context.Background())
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
@@ -0,0 +1,64 @@
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/georgysavva/scany/sqlscan"
+)
+
+// SQLTransaction - A store bound to an open `database/sql` transaction.
+// Obtained by calling `Transaction()` on stores built on top of `database/sql`.
+type SQLTransaction struct {
+	tx    *sql.Tx
+	name  string
+	depth int
+	URL   string
+}
+
+func (store SQLTransaction) Name() string {
+	return store.name
+}
+
+func (store SQLTransaction) DatabaseURL() string {
+	return store.URL
+}
+
+func (store SQLTransaction) Create(query string, options ...interface{}) error {
+	_, err := store.tx.Exec(query, options...)
+	return err
+}
+
+func (store SQLTransaction) Read(query string, model interface{}, options ...interface{}) error {
+	return sqlscan.Select(context.Background(), store.tx, model, query, options...)
+}
+
+func (store SQLTransaction) Delete(query string, options ...interface{}) error {
+	_, err := store.tx.Exec(query, options...)
+	return err
+}
+
+// Transaction - Nested transactions are implemented with savepoints.
+func (store SQLTransaction) Transaction(fn func(Store) error) error {
+	savepoint := fmt.Sprintf("dm_savepoint_%v", store.depth+1)
+
+	_, err := store.tx.Exec(fmt.Sprintf("SAVEPOINT %v;", savepoint))
+
+	if err != nil {
+		return err
+	}
+
+	nested := store
+	nested.depth += 1
+
+	err = fn(nested)
+
+	if err != nil {
+		_, _ = store.tx.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT %v;", savepoint))
+		return err
+	}
+
+	_, err = store.tx.Exec(fmt.Sprintf("RELEASE SAVEPOINT %v;", savepoint))
+	return err
+}
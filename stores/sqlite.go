@@ -0,0 +1,121 @@
+package stores
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/georgysavva/scany/sqlscan"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLite3 - A store backed by a SQLite database file.
+// The URL can be a file path or a DSN (i.e. file:dev.db?_foreign_keys=on), optionally prefixed with `sqlite3://`.
+// Every operation opens its own connection, so in-memory databases are refused. See `dataSourceName()`.
+type SQLite3 struct {
+	instance *sql.DB
+	URL      string
+}
+
+func (store *SQLite3) Connect() error {
+	dsn, err := store.dataSourceName()
+
+	if err != nil {
+		return err
+	}
+
+	conn, err := sql.Open("sqlite3", dsn)
+
+	store.instance = conn
+
+	return err
+}
+
+func (store *SQLite3) Disconnect() error {
+	return store.instance.Close()
+}
+
+func (store SQLite3) Name() string {
+	return "SQLite3"
+}
+
+func (store SQLite3) DatabaseURL() string {
+	return store.URL
+}
+
+func (store SQLite3) Create(query string, options ...interface{}) error {
+	err := store.Connect()
+
+	if err != nil {
+		return err
+	}
+
+	defer store.Disconnect()
+
+	_, err = store.instance.Exec(query, options...)
+	return err
+}
+
+func (store SQLite3) Read(query string, model interface{}, options ...interface{}) error {
+	err := store.Connect()
+
+	if err != nil {
+		return err
+	}
+
+	defer store.Disconnect()
+
+	return sqlscan.Select(context.Background(), store.instance, model, query, options...)
+}
+
+func (store SQLite3) Delete(query string, options ...interface{}) error {
+	err := store.Connect()
+
+	if err != nil {
+		return err
+	}
+
+	defer store.Disconnect()
+
+	_, err = store.instance.Exec(query, options...)
+	return err
+}
+
+func (store SQLite3) Transaction(fn func(Store) error) error {
+	err := store.Connect()
+
+	if err != nil {
+		return err
+	}
+
+	defer store.Disconnect()
+
+	tx, err := store.instance.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	err = fn(SQLTransaction{tx: tx, name: store.Name(), URL: store.URL})
+
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dataSourceName - Converts the store's URL into a DSN understood by the driver.
+// In-memory and temporary databases are discarded when their connection is closed, so their tables would not outlive a single operation.
+func (store SQLite3) dataSourceName() (string, error) {
+	dsn := strings.TrimPrefix(store.URL, "sqlite3://")
+	path, query, _ := strings.Cut(dsn, "?")
+
+	if path == "" || path == ":memory:" || strings.HasPrefix(path, "file::memory:") || strings.Contains("&"+query+"&", "&mode=memory&") {
+		return "", fmt.Errorf("in-memory SQLite databases are not supported (%v), since they are discarded between operations. Use a database file instead", store.URL)
+	}
+
+	return dsn, nil
+}
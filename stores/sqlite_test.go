@@ -0,0 +1,173 @@
+package stores
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+type item struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+// testSQLite3 - A store for a new SQLite database with an empty items table.
+func testSQLite3(t *testing.T) SQLite3 {
+	store := SQLite3{URL: filepath.Join(t.TempDir(), "test.db")}
+
+	if err := store.Create("CREATE TABLE items (id INTEGER PRIMARY KEY, name VARCHAR NOT NULL);"); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	return store
+}
+
+func readItems(t *testing.T, store Store) []item {
+	items := []item{}
+
+	if err := store.Read("SELECT id, name FROM items ORDER BY id;", &items); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	return items
+}
+
+// ----------------------------------
+
+func TestSQLite3(t *testing.T) {
+	store := testSQLite3(t)
+
+	// Scenario 1: Records are kept between operations
+	if err := store.Create("INSERT INTO items (id, name) VALUES (?1, ?2), (?3, ?4);", 1, "one", 2, "two"); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if items := readItems(t, store); len(items) != 2 || items[0].Name != "one" || items[1].Name != "two" {
+		t.Errorf(`expected 2 items, but got %+v`, items)
+	}
+
+	// Scenario 2: Records are deleted
+	if err := store.Delete("DELETE FROM items WHERE id = ?1;", 1); err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if items := readItems(t, store); len(items) != 1 || items[0].ID != 2 {
+		t.Errorf(`expected 1 item, but got %+v`, items)
+	}
+
+	// Scenario 3: Invalid queries are reported
+	if err := store.Read("SELECT * FROM missing;", &[]item{}); err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+
+	// Scenario 4: The URL can be prefixed with the adapter
+	prefixed := SQLite3{URL: "sqlite3://" + store.URL}
+
+	if items := readItems(t, prefixed); len(items) != 1 {
+		t.Errorf(`expected 1 item, but got %+v`, items)
+	}
+}
+
+func TestSQLite3Transaction(t *testing.T) {
+	store := testSQLite3(t)
+
+	// Scenario 1: Changes are committed when the function succeeds
+	err := store.Transaction(func(tx Store) error {
+		if err := tx.Create("INSERT INTO items (id, name) VALUES (?1, ?2);", 1, "one"); err != nil {
+			return err
+		}
+
+		// NOTE: Changes are visible within the transaction
+		if items := readItems(t, tx); len(items) != 1 {
+			t.Errorf(`expected 1 item within the transaction, but got %+v`, items)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf(`expected no errors, but got %v`, err)
+	}
+
+	if items := readItems(t, store); len(items) != 1 {
+		t.Errorf(`expected 1 item, but got %+v`, items)
+	}
+
+	// Scenario 2: Changes are rolled back when the function fails
+	failure := errors.New("failure")
+
+	err = store.Transaction(func(tx Store) error {
+		if err := tx.Create("INSERT INTO items (id, name) VALUES (?1, ?2);", 2, "two"); err != nil {
+			return err
+		}
+
+		if err := tx.Delete("DELETE FROM items WHERE id = ?1;", 1); err != nil {
+			return err
+		}
+
+		return failure
+	})
+
+	if !errors.Is(err, failure) {
+		t.Errorf(`expected %v, but got %v`, failure, err)
+	}
+
+	if items := readItems(t, store); len(items) != 1 || items[0].ID != 1 {
+		t.Errorf(`expected the changes to be rolled back, but got %+v`, items)
+	}
+
+	// Scenario 3: Changes are rolled back when a statement fails
+	err = store.Transaction(func(tx Store) error {
+		if err := tx.Create("INSERT INTO items (id, name) VALUES (?1, ?2);", 2, "two"); err != nil {
+			return err
+		}
+
+		return tx.Create("INSERT INTO items (id, name) VALUES (?1, ?2);", 1, "duplicate")
+	})
+
+	if err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+
+	if items := readItems(t, store); len(items) != 1 || items[0].Name != "one" {
+		t.Errorf(`expected the changes to be rolled back, but got %+v`, items)
+	}
+}
+
+func TestSQLite3InMemory(t *testing.T) {
+	scenarios := []struct {
+		url      string
+		inMemory bool
+	}{
+		{":memory:", true},
+		{"sqlite3://:memory:", true},
+		{"file::memory:?cache=shared", true},
+		{"file:dev.db?mode=memory", true},
+		{"file:dev.db?cache=shared&mode=memory", true},
+		{"", true},
+		{"dev.db", false},
+		{"sqlite3://dev.db", false},
+		{"file:dev.db?_foreign_keys=on", false},
+		{"file:dev.db?mode=rwc", false},
+	}
+
+	for _, scenario := range scenarios {
+		store := SQLite3{URL: scenario.url}
+		_, err := store.dataSourceName()
+
+		if (err != nil) != scenario.inMemory {
+			t.Errorf(`expected in-memory to be %v for '%v', but got %v`, scenario.inMemory, scenario.url, err)
+		}
+	}
+
+	// NOTE: In-memory databases are refused rather than silently losing their tables between operations
+	store := SQLite3{URL: ":memory:"}
+
+	if err := store.Create("CREATE TABLE items (id INTEGER);"); err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+
+	if err := store.Transaction(func(Store) error { return nil }); err == nil {
+		t.Errorf(`expected an error, but got %v`, err)
+	}
+}
@@ -0,0 +1,21 @@
+package stores
+
+type Store interface {
+	// Create - Adds record(s) to the store.
+	Create(string, ...interface{}) error
+
+	// Read - Reads record from the store.
+	Read(string, interface{}, ...interface{}) error
+
+	// Delete - Removes record(s) from the store.
+	Delete(string, ...interface{}) error
+
+	// Transaction - Runs the provided function within a single database transaction.
+	// Changes are committed if the function returns nil and rolled back otherwise.
+	Transaction(func(Store) error) error
+
+	// Name - A string that identifies this store.
+	Name() string
+
+	DatabaseURL() string
+}